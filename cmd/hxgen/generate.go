@@ -0,0 +1,804 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const hxImportPath = "github.com/thisisthemurph/hx"
+
+// event is a trigger event found in the scanned packages.
+type event struct {
+	name   string // The name of the event.
+	detail string // The TypeScript type of the event detail.
+	pos    string // The position of the definition, used in error messages.
+}
+
+// pkgScope holds the declarations of a single package.
+type pkgScope struct {
+	dir     string
+	name    string
+	syntax  []*ast.File
+	files   map[*ast.File]*fileScope
+	types   map[string]*ast.TypeSpec
+	decls   map[string]*fileScope      // The file declaring each type, keyed by type name.
+	methods map[string]map[string]bool // Method names keyed by receiver type name.
+}
+
+// fileScope holds the imports of a single file, used to resolve qualified type names.
+type fileScope struct {
+	pkg     *pkgScope
+	named   map[string]string // Import paths keyed by their explicit name.
+	unnamed []string          // Import paths without an explicit name.
+}
+
+// detailKind describes the JSON values a Go type encodes to, which determines how HTMX dispatches
+// the value as event detail.
+type detailKind int
+
+const (
+	kindUnknown        detailKind = iota // The value may or may not be a JSON object.
+	kindObject                           // The value is always a JSON object.
+	kindNullableObject                   // The value is a JSON object or null.
+	kindValue                            // The value is never a JSON object, such as a string, number or array.
+	kindNullableValue                    // The value is never a JSON object, and may be null, such as a slice.
+)
+
+// knownType is the TypeScript type of a type from the standard library with custom JSON encoding.
+type knownType struct {
+	ts   string
+	kind detailKind
+}
+
+var knownTypes = map[string]knownType{
+	"time.Time":                {"string", kindValue},
+	"time.Duration":            {"number", kindValue},
+	"encoding/json.RawMessage": {"unknown", kindUnknown},
+	"encoding/json.Number":     {"number", kindValue},
+}
+
+// generator scans packages for event definitions and generates TypeScript declarations.
+type generator struct {
+	fset     *token.FileSet
+	events   map[string]event
+	decls    map[string]string    // TypeScript declarations keyed by the Go type key.
+	names    map[string]string    // TypeScript names keyed by the Go type key.
+	keys     map[string]string    // Go type keys keyed by TypeScript name, to detect collisions.
+	scopes   map[string]*pkgScope // Loaded packages keyed by absolute directory.
+	imports  map[string]*pkgScope // Imported packages keyed by import path; nil if the package cannot be loaded.
+	warnings []string
+	warned   map[string]bool
+}
+
+func newGenerator() *generator {
+	return &generator{
+		fset:    token.NewFileSet(),
+		events:  make(map[string]event),
+		decls:   make(map[string]string),
+		names:   make(map[string]string),
+		keys:    make(map[string]string),
+		scopes:  make(map[string]*pkgScope),
+		imports: make(map[string]*pkgScope),
+		warned:  make(map[string]bool),
+	}
+}
+
+// warn records a warning for the given position, ignoring duplicates.
+func (g *generator) warn(pos token.Pos, format string, args ...any) {
+	msg := fmt.Sprintf("%s: %s", g.fset.Position(pos), fmt.Sprintf(format, args...))
+	if g.warned[msg] {
+		return
+	}
+	g.warned[msg] = true
+	g.warnings = append(g.warnings, msg)
+}
+
+// expandPatterns converts the package patterns into a list of directories.
+// A pattern ending in "/..." matches the directory and all subdirectories containing Go files.
+func expandPatterns(patterns []string) ([]string, error) {
+	dirs := make([]string, 0)
+	for _, pattern := range patterns {
+		root, recursive := strings.CutSuffix(pattern, "...")
+		if !recursive {
+			dirs = append(dirs, pattern)
+			continue
+		}
+
+		root = filepath.Clean(strings.TrimSuffix(root, "/"))
+		if root == "" {
+			root = "."
+		}
+
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() {
+				return nil
+			}
+			name := d.Name()
+			if path != root && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor") {
+				return filepath.SkipDir
+			}
+			if hasGoFiles(path) {
+				dirs = append(dirs, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return dirs, nil
+}
+
+func hasGoFiles(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if isSourceFile(entry) {
+			return true
+		}
+	}
+	return false
+}
+
+func isSourceFile(entry fs.DirEntry) bool {
+	name := entry.Name()
+	return !entry.IsDir() && strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go")
+}
+
+// scanDir parses the Go files of the package in the directory and records any event definitions.
+func (g *generator) scanDir(dir string) error {
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil {
+		var noGo *build.NoGoError
+		if errors.As(err, &noGo) {
+			return nil
+		}
+		return err
+	}
+
+	scope, err := g.load(bp)
+	if err != nil {
+		return err
+	}
+	for _, file := range scope.syntax {
+		if err := g.scanFile(scope.files[file], file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// load parses the Go files of the package, recording its type declarations and methods.
+// Packages are only loaded once.
+func (g *generator) load(bp *build.Package) (*pkgScope, error) {
+	dir, err := filepath.Abs(bp.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if scope, ok := g.scopes[dir]; ok {
+		return scope, nil
+	}
+
+	scope := &pkgScope{
+		dir:     dir,
+		name:    bp.Name,
+		files:   make(map[*ast.File]*fileScope),
+		types:   make(map[string]*ast.TypeSpec),
+		decls:   make(map[string]*fileScope),
+		methods: make(map[string]map[string]bool),
+	}
+	for _, name := range bp.GoFiles {
+		file, err := parser.ParseFile(g.fset, filepath.Join(bp.Dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		fscope := newFileScope(scope, file)
+		scope.syntax = append(scope.syntax, file)
+		scope.files[file] = fscope
+
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				if decl.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range decl.Specs {
+					ts := spec.(*ast.TypeSpec)
+					scope.types[ts.Name.Name] = ts
+					scope.decls[ts.Name.Name] = fscope
+				}
+			case *ast.FuncDecl:
+				if recv := receiverName(decl); recv != "" {
+					if scope.methods[recv] == nil {
+						scope.methods[recv] = make(map[string]bool)
+					}
+					scope.methods[recv][decl.Name.Name] = true
+				}
+			}
+		}
+	}
+
+	g.scopes[dir] = scope
+	return scope, nil
+}
+
+// importPackage loads the package with the given import path, as imported from the directory.
+// It returns nil if the package cannot be found.
+func (g *generator) importPackage(importPath, fromDir string) *pkgScope {
+	if scope, ok := g.imports[importPath]; ok {
+		return scope
+	}
+
+	var scope *pkgScope
+	if bp, err := build.Import(importPath, fromDir, 0); err == nil {
+		scope, _ = g.load(bp)
+	}
+	g.imports[importPath] = scope
+	return scope
+}
+
+func newFileScope(pkg *pkgScope, file *ast.File) *fileScope {
+	fscope := &fileScope{pkg: pkg, named: make(map[string]string)}
+	for _, imp := range file.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		if imp.Name != nil {
+			fscope.named[imp.Name.Name] = importPath
+		} else {
+			fscope.unnamed = append(fscope.unnamed, importPath)
+		}
+	}
+	return fscope
+}
+
+// receiverName returns the name of the receiver type of a method, or an empty string for functions.
+func receiverName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return ""
+	}
+	typ := fn.Recv.List[0].Type
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	switch t := typ.(type) {
+	case *ast.IndexExpr:
+		typ = t.X
+	case *ast.IndexListExpr:
+		typ = t.X
+	}
+	if ident, ok := typ.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// scanFile records the event definitions in the file.
+func (g *generator) scanFile(fscope *fileScope, file *ast.File) error {
+	alias := ""
+	for name, importPath := range fscope.named {
+		if importPath == hxImportPath {
+			alias = name
+		}
+	}
+	if slices.Contains(fscope.unnamed, hxImportPath) {
+		alias = "hx"
+	}
+	if alias == "" && fscope.pkg.name != "hx" {
+		return nil
+	}
+
+	var err error
+	ast.Inspect(file, func(n ast.Node) bool {
+		if err != nil {
+			return false
+		}
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		typeArg, ok := defineEventTypeArg(call, alias)
+		if !ok {
+			return true
+		}
+		err = g.addEvent(fscope, call, typeArg)
+		return true
+	})
+	return err
+}
+
+// defineEventTypeArg returns the type argument of the call if it is a call to hx.DefineEvent.
+func defineEventTypeArg(call *ast.CallExpr, alias string) (ast.Expr, bool) {
+	index, ok := call.Fun.(*ast.IndexExpr)
+	if !ok {
+		return nil, false
+	}
+
+	switch fn := index.X.(type) {
+	case *ast.SelectorExpr:
+		pkg, ok := fn.X.(*ast.Ident)
+		if !ok || alias == "" || pkg.Name != alias || fn.Sel.Name != "DefineEvent" {
+			return nil, false
+		}
+	case *ast.Ident:
+		if alias != "" || fn.Name != "DefineEvent" {
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+	return index.Index, true
+}
+
+func (g *generator) addEvent(fscope *fileScope, call *ast.CallExpr, typeArg ast.Expr) error {
+	pos := g.fset.Position(call.Pos()).String()
+	if len(call.Args) != 1 {
+		return fmt.Errorf("%s: DefineEvent expects a single event name", pos)
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return fmt.Errorf("%s: event name must be a string literal", pos)
+	}
+	name, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", pos, err)
+	}
+
+	detail := g.detailType(fscope, typeArg)
+	if existing, ok := g.events[name]; ok {
+		if existing.detail != detail {
+			return fmt.Errorf("%s: event %q is already defined with a different detail at %s", pos, name, existing.pos)
+		}
+		return nil
+	}
+
+	g.events[name] = event{name: name, detail: detail, pos: pos}
+	return nil
+}
+
+// detailType returns the TypeScript type of the detail received by event listeners for the Go type.
+//
+// HTMX dispatches JSON object detail as it is, but wraps any other value, including arrays and null,
+// in an object with a value property. Slices, maps and pointers encode to null when nil.
+func (g *generator) detailType(fscope *fileScope, expr ast.Expr) string {
+	switch g.kindOf(fscope, expr, make(map[string]bool)) {
+	case kindObject:
+		return g.tsType(fscope, expr)
+	case kindNullableObject:
+		return g.tsType(fscope, deref(expr)) + " | { value: null }"
+	case kindValue:
+		return "{ value: " + g.tsType(fscope, expr) + " }"
+	case kindNullableValue:
+		return "{ value: " + g.tsType(fscope, expr) + " | null }"
+	default:
+		return g.tsType(fscope, expr)
+	}
+}
+
+// deref removes any parentheses and pointers from the type expression.
+func deref(expr ast.Expr) ast.Expr {
+	for {
+		switch e := expr.(type) {
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.StarExpr:
+			expr = e.X
+		default:
+			return expr
+		}
+	}
+}
+
+// kindOf returns the kind of JSON values the Go type expression encodes to.
+// The seen map guards against recursive named types.
+func (g *generator) kindOf(fscope *fileScope, expr ast.Expr, seen map[string]bool) detailKind {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if _, ok := basicTypes[e.Name]; ok {
+			if e.Name == "any" || e.Name == "error" {
+				return kindUnknown
+			}
+			return kindValue
+		}
+		if _, ok := fscope.pkg.types[e.Name]; ok {
+			return g.namedKind(fscope.pkg, e.Name, seen)
+		}
+		return kindUnknown
+	case *ast.ParenExpr:
+		return g.kindOf(fscope, e.X, seen)
+	case *ast.StarExpr:
+		switch g.kindOf(fscope, e.X, seen) {
+		case kindObject, kindNullableObject:
+			return kindNullableObject
+		case kindValue, kindNullableValue:
+			// The TypeScript type of the pointer already includes null.
+			return kindValue
+		default:
+			return kindUnknown
+		}
+	case *ast.ArrayType:
+		if e.Len == nil {
+			return kindNullableValue
+		}
+		return kindValue
+	case *ast.MapType:
+		return kindNullableObject
+	case *ast.StructType:
+		return kindObject
+	case *ast.SelectorExpr:
+		pkg, ok := e.X.(*ast.Ident)
+		if !ok {
+			return kindUnknown
+		}
+		importPath, scope := g.resolveImport(fscope, pkg.Name, e.Sel.Name)
+		if known, ok := knownTypes[importPath+"."+e.Sel.Name]; ok {
+			return known.kind
+		}
+		if scope != nil {
+			return g.namedKind(scope, e.Sel.Name, seen)
+		}
+		return kindUnknown
+	default:
+		return kindUnknown
+	}
+}
+
+// namedKind returns the kind of JSON values the named type declared in the package encodes to.
+func (g *generator) namedKind(scope *pkgScope, name string, seen map[string]bool) detailKind {
+	key := scope.dir + "." + name
+	spec, ok := scope.types[name]
+	if !ok || seen[key] || spec.TypeParams != nil {
+		return kindUnknown
+	}
+	seen[key] = true
+
+	switch {
+	case scope.methods[name]["MarshalJSON"]:
+		return kindUnknown
+	case scope.methods[name]["MarshalText"]:
+		return kindValue
+	}
+	return g.kindOf(scope.decls[name], spec.Type, seen)
+}
+
+// resolveImport returns the import path of the package referred to by name in the file, and the
+// package if it declares the named type. Imports without an explicit name are matched by the package
+// name, loading the packages as necessary. Known types are resolved without loading the package.
+func (g *generator) resolveImport(fscope *fileScope, name, typeName string) (string, *pkgScope) {
+	candidates := make([]string, 0)
+	explicit := false
+	if importPath, ok := fscope.named[name]; ok {
+		candidates = append(candidates, importPath)
+		explicit = true
+	} else {
+		// Try the imports whose path suggests the package name first, to avoid loading unrelated packages.
+		for _, importPath := range fscope.unnamed {
+			if guessPackageName(importPath) == name {
+				candidates = append(candidates, importPath)
+			}
+		}
+		for _, importPath := range fscope.unnamed {
+			if guessPackageName(importPath) != name {
+				candidates = append(candidates, importPath)
+			}
+		}
+	}
+
+	for _, importPath := range candidates {
+		if _, ok := knownTypes[importPath+"."+typeName]; ok && (explicit || guessPackageName(importPath) == name) {
+			return importPath, nil
+		}
+		scope := g.importPackage(importPath, fscope.pkg.dir)
+		if scope == nil || (!explicit && scope.name != name) {
+			continue
+		}
+		if _, ok := scope.types[typeName]; ok {
+			return importPath, scope
+		}
+		return importPath, nil
+	}
+	return "", nil
+}
+
+// guessPackageName returns the likely package name for the import path: the last path element,
+// ignoring any major version suffix and "go-" prefix.
+func guessPackageName(importPath string) string {
+	name := path.Base(importPath)
+	if len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = path.Base(path.Dir(importPath))
+	}
+	name = strings.TrimPrefix(name, "go-")
+	if i := strings.IndexAny(name, ".-"); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// tsType returns the TypeScript type for the Go type expression, following the rules of encoding/json.
+// Types which cannot be resolved are given the type unknown, and a warning is recorded.
+func (g *generator) tsType(fscope *fileScope, expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if t, ok := basicTypes[e.Name]; ok {
+			return t
+		}
+		if _, ok := fscope.pkg.types[e.Name]; ok {
+			return g.named(fscope.pkg, e.Name)
+		}
+		g.warn(e.Pos(), "cannot resolve type %s; using unknown", e.Name)
+		return "unknown"
+	case *ast.ParenExpr:
+		return g.tsType(fscope, e.X)
+	case *ast.StarExpr:
+		return g.tsType(fscope, e.X) + " | null"
+	case *ast.ArrayType:
+		if ident, ok := e.Elt.(*ast.Ident); ok && ident.Name == "byte" && e.Len == nil {
+			return "string"
+		}
+		elem := g.tsType(fscope, e.Elt)
+		if strings.Contains(elem, "|") {
+			elem = "(" + elem + ")"
+		}
+		return elem + "[]"
+	case *ast.MapType:
+		return "Record<string, " + g.tsType(fscope, e.Value) + ">"
+	case *ast.StructType:
+		fields := g.fields(fscope, e)
+		if len(fields) == 0 {
+			return "{}"
+		}
+		return "{ " + strings.Join(fields, "; ") + " }"
+	case *ast.InterfaceType:
+		return "unknown"
+	case *ast.SelectorExpr:
+		pkg, ok := e.X.(*ast.Ident)
+		if !ok {
+			break
+		}
+		importPath, scope := g.resolveImport(fscope, pkg.Name, e.Sel.Name)
+		if known, ok := knownTypes[importPath+"."+e.Sel.Name]; ok {
+			return known.ts
+		}
+		if scope != nil {
+			return g.named(scope, e.Sel.Name)
+		}
+		g.warn(e.Pos(), "cannot resolve type %s.%s; using unknown", pkg.Name, e.Sel.Name)
+		return "unknown"
+	}
+
+	g.warn(expr.Pos(), "unsupported type %s; using unknown", types.ExprString(expr))
+	return "unknown"
+}
+
+var basicTypes = map[string]string{
+	"string": "string", "bool": "boolean",
+	"int": "number", "int8": "number", "int16": "number", "int32": "number", "int64": "number",
+	"uint": "number", "uint8": "number", "uint16": "number", "uint32": "number", "uint64": "number",
+	"uintptr": "number", "float32": "number", "float64": "number", "byte": "number", "rune": "number",
+	"any": "unknown", "error": "unknown",
+}
+
+// named returns the TypeScript name for a type declared in the package, declaring it if necessary.
+func (g *generator) named(scope *pkgScope, name string) string {
+	key := scope.dir + "." + name
+	if tsName, ok := g.names[key]; ok {
+		return tsName
+	}
+
+	tsName := name
+	if other, ok := g.keys[tsName]; ok && other != key {
+		tsName = exportedName(scope.name) + name
+	}
+	g.names[key] = tsName
+	g.keys[tsName] = key
+
+	spec := scope.types[name]
+	switch {
+	case spec.TypeParams != nil:
+		g.warn(spec.Pos(), "generic type %s is not supported; using unknown", name)
+		g.decls[key] = fmt.Sprintf("export type %s = unknown;\n", tsName)
+		return tsName
+	case scope.methods[name]["MarshalJSON"]:
+		g.warn(spec.Pos(), "type %s implements json.Marshaler; using unknown", name)
+		g.decls[key] = fmt.Sprintf("export type %s = unknown;\n", tsName)
+		return tsName
+	case scope.methods[name]["MarshalText"]:
+		g.decls[key] = fmt.Sprintf("export type %s = string;\n", tsName)
+		return tsName
+	}
+
+	// Record a placeholder before resolving the type, allowing self-referential types.
+	g.decls[key] = ""
+	fscope := scope.decls[name]
+	if st, ok := spec.Type.(*ast.StructType); ok {
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, "export interface %s {\n", tsName)
+		for _, field := range g.fields(fscope, st) {
+			fmt.Fprintf(&buf, "  %s;\n", field)
+		}
+		buf.WriteString("}\n")
+		g.decls[key] = buf.String()
+	} else {
+		g.decls[key] = fmt.Sprintf("export type %s = %s;\n", tsName, g.tsType(fscope, spec.Type))
+	}
+	return tsName
+}
+
+// fields returns the TypeScript property signatures for the struct, honouring json tags and
+// flattening embedded structs as encoding/json does.
+func (g *generator) fields(fscope *fileScope, st *ast.StructType) []string {
+	fields := make([]string, 0)
+	for _, field := range st.Fields.List {
+		tag := ""
+		if field.Tag != nil {
+			raw, _ := strconv.Unquote(field.Tag.Value)
+			tag = reflect.StructTag(raw).Get("json")
+		}
+		if tag == "-" {
+			continue
+		}
+		tagName, opts, _ := strings.Cut(tag, ",")
+		optional := hasOption(opts, "omitempty") || hasOption(opts, "omitzero")
+
+		if len(field.Names) == 0 {
+			if tagName == "" {
+				if embeddedScope, embedded, ok := g.embeddedStruct(fscope, field.Type); ok {
+					fields = append(fields, g.fields(embeddedScope, embedded)...)
+					continue
+				}
+			}
+			name := embeddedName(field.Type)
+			if name == "" || (!ast.IsExported(name) && tagName == "") {
+				continue
+			}
+			fields = append(fields, g.property(fscope, field.Type, name, tagName, opts, optional))
+			continue
+		}
+
+		for _, ident := range field.Names {
+			if !ast.IsExported(ident.Name) {
+				continue
+			}
+			fields = append(fields, g.property(fscope, field.Type, ident.Name, tagName, opts, optional))
+		}
+	}
+	return fields
+}
+
+func (g *generator) property(fscope *fileScope, typ ast.Expr, name, tagName, opts string, optional bool) string {
+	if tagName != "" {
+		name = tagName
+	}
+	t := g.tsType(fscope, typ)
+	if hasOption(opts, "string") {
+		t = "string"
+	}
+	if optional {
+		return propertyName(name) + "?: " + t
+	}
+	return propertyName(name) + ": " + t
+}
+
+// embeddedStruct returns the struct type of an embedded field, and the scope of the file declaring it.
+func (g *generator) embeddedStruct(fscope *fileScope, typ ast.Expr) (*fileScope, *ast.StructType, bool) {
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+
+	scope, name := fscope.pkg, ""
+	switch t := typ.(type) {
+	case *ast.Ident:
+		name = t.Name
+	case *ast.SelectorExpr:
+		pkg, ok := t.X.(*ast.Ident)
+		if !ok {
+			return nil, nil, false
+		}
+		_, scope = g.resolveImport(fscope, pkg.Name, t.Sel.Name)
+		name = t.Sel.Name
+	}
+	if scope == nil || scope.methods[name]["MarshalJSON"] || scope.methods[name]["MarshalText"] {
+		return nil, nil, false
+	}
+
+	spec, ok := scope.types[name]
+	if !ok {
+		return nil, nil, false
+	}
+	st, ok := spec.Type.(*ast.StructType)
+	return scope.decls[name], st, ok
+}
+
+func embeddedName(typ ast.Expr) string {
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	switch t := typ.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	default:
+		return ""
+	}
+}
+
+func hasOption(opts, option string) bool {
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+func propertyName(name string) string {
+	if identifierPattern.MatchString(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
+
+func exportedName(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// generate returns the TypeScript declarations for all events found.
+func (g *generator) generate() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by hxgen. DO NOT EDIT.\n\n")
+
+	tsNames := make([]string, 0, len(g.keys))
+	for tsName := range g.keys {
+		tsNames = append(tsNames, tsName)
+	}
+	sort.Strings(tsNames)
+	for _, tsName := range tsNames {
+		buf.WriteString(g.decls[g.keys[tsName]])
+		buf.WriteString("\n")
+	}
+
+	names := make([]string, 0, len(g.events))
+	for name := range g.events {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf.WriteString("declare global {\n")
+	buf.WriteString("  interface HTMLElementEventMap {\n")
+	for _, name := range names {
+		fmt.Fprintf(&buf, "    %s: CustomEvent<%s>;\n", strconv.Quote(name), g.events[name].detail)
+	}
+	buf.WriteString("  }\n")
+	buf.WriteString("}\n\n")
+	buf.WriteString("export {};\n")
+
+	return buf.Bytes()
+}
@@ -0,0 +1,87 @@
+// Command hxgen generates TypeScript declarations for trigger events defined with hx.DefineEvent.
+//
+// hxgen scans the Go source of the given package directories for calls such as
+//
+//	var ItemAdded = hx.DefineEvent[ItemAddedDetail]("item-added")
+//
+// and writes a .d.ts file augmenting HTMLElementEventMap, so that listeners for the events receive
+// a CustomEvent with a detail matching the Go type, honouring json struct tags.
+//
+// HTMX dispatches detail which is a JSON object as it is, and wraps any other detail, such as a
+// number, string, array or null, as {value: detail}. The declarations follow the same rules, so a
+// DefineEvent[int] event has the detail type { value: number }, and a DefineEvent[*T] event, where
+// T is a struct, has the detail type T | { value: null }.
+//
+// Types declared in other packages are resolved by loading the imported package from source with
+// go/build, so hxgen must be run from within the module. Type checking is not performed: types
+// which cannot be resolved, generic types, types implementing json.Marshaler, and channel and
+// function types are given the type unknown, and a warning naming the position is written to
+// standard error. Types implementing encoding.TextMarshaler are declared as strings.
+//
+// Usage:
+//
+//	hxgen [-o events.d.ts] [packages]
+//
+// Packages are directories; a directory ending in "/..." is scanned recursively. If no packages are
+// given, the current directory is scanned. If no output file is given, the declarations are written
+// to standard output.
+//
+// hxgen is typically invoked with go generate:
+//
+//	//go:generate go run github.com/thisisthemurph/hx/cmd/hxgen -o ../web/src/events.d.ts ./...
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "hxgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("hxgen", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	output := flags.String("o", "", "output file; defaults to standard output")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: hxgen [-o events.d.ts] [packages]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	patterns := flags.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+
+	dirs, err := expandPatterns(patterns)
+	if err != nil {
+		return err
+	}
+
+	g := newGenerator()
+	for _, dir := range dirs {
+		if err := g.scanDir(dir); err != nil {
+			return err
+		}
+	}
+
+	for _, warning := range g.warnings {
+		fmt.Fprintln(stderr, "hxgen: warning:", warning)
+	}
+
+	out := g.generate()
+	if *output == "" {
+		_, err = stdout.Write(out)
+		return err
+	}
+	return os.WriteFile(*output, out, 0o644)
+}
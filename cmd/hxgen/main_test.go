@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	expected, err := os.ReadFile("testdata/events.d.ts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	var warnings bytes.Buffer
+	err = run([]string{"./testdata/..."}, &out, &warnings)
+
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), out.String())
+	assert.Empty(t, warnings.String())
+}
+
+func TestRun_WritesOutputFile(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "events.d.ts")
+
+	err := run([]string{"-o", output, "./testdata/events/nested"}, &bytes.Buffer{}, &bytes.Buffer{})
+	assert.NoError(t, err)
+
+	data, err := os.ReadFile(output)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"refreshed": CustomEvent<{ value: ToastDetail[] | null }>;`)
+	assert.Contains(t, string(data), "declare global {\n  interface HTMLElementEventMap {")
+}
+
+func TestRun_ReturnsErrorForNonLiteralEventName(t *testing.T) {
+	dir := t.TempDir()
+	source := `package events
+
+import "github.com/thisisthemurph/hx"
+
+const name = "item-added"
+
+var ItemAdded = hx.DefineEvent[string](name)
+`
+	if err := os.WriteFile(filepath.Join(dir, "events.go"), []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := run([]string{dir}, &bytes.Buffer{}, &bytes.Buffer{})
+
+	assert.ErrorContains(t, err, "event name must be a string literal")
+}
+
+func TestRun_ReturnsErrorForConflictingDefinitions(t *testing.T) {
+	dir := t.TempDir()
+	source := `package events
+
+import "github.com/thisisthemurph/hx"
+
+var (
+	First  = hx.DefineEvent[string]("item-added")
+	Second = hx.DefineEvent[int]("item-added")
+)
+`
+	if err := os.WriteFile(filepath.Join(dir, "events.go"), []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := run([]string{dir}, &bytes.Buffer{}, &bytes.Buffer{})
+
+	assert.ErrorContains(t, err, `event "item-added" is already defined with a different detail`)
+}
+
+func TestRun_WarnsForUnresolvedTypes(t *testing.T) {
+	dir := t.TempDir()
+	source := `package events
+
+import (
+	"github.com/thisisthemurph/hx"
+	"example.com/missing"
+)
+
+var (
+	Selected = hx.DefineEvent[missing.Item]("item-selected")
+	Removed  = hx.DefineEvent[[]Unknown]("item-removed")
+)
+`
+	if err := os.WriteFile(filepath.Join(dir, "events.go"), []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out, warnings bytes.Buffer
+	err := run([]string{dir}, &out, &warnings)
+
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), `"item-selected": CustomEvent<unknown>;`)
+	assert.Contains(t, out.String(), `"item-removed": CustomEvent<{ value: unknown[] | null }>;`)
+	assert.Contains(t, warnings.String(), "events.go:9:28: cannot resolve type missing.Item; using unknown")
+	assert.Contains(t, warnings.String(), "events.go:10:30: cannot resolve type Unknown; using unknown")
+}
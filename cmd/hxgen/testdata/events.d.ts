@@ -0,0 +1,50 @@
+// Code generated by hxgen. DO NOT EDIT.
+
+export interface Item {
+  id: number;
+  name: string;
+  status: Status;
+}
+
+export interface ItemAddedDetail {
+  createdBy: string;
+  createdAt: string;
+  id: number;
+  name: string;
+  price?: number;
+  tags: string[];
+  meta?: Record<string, unknown>;
+  parent: ItemAddedDetail | null;
+  count: string;
+  Untagged: boolean;
+  "data-value": string;
+}
+
+export type Level = string;
+
+export interface NestedToastDetail {
+  title: string;
+}
+
+export type Status = string;
+
+export interface ToastDetail {
+  level: Level;
+  message: string;
+  timeout: number;
+}
+
+declare global {
+  interface HTMLElementEventMap {
+    "filtered": CustomEvent<Record<string, Item[]> | { value: null }>;
+    "inline": CustomEvent<{ ok: boolean }>;
+    "item-added": CustomEvent<ItemAddedDetail>;
+    "item-removed": CustomEvent<{ value: number }>;
+    "item-selected": CustomEvent<Item>;
+    "refreshed": CustomEvent<{ value: NestedToastDetail[] | null }>;
+    "showMessage": CustomEvent<ToastDetail>;
+    "toast-closed": CustomEvent<ToastDetail | { value: null }>;
+  }
+}
+
+export {};
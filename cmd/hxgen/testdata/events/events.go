@@ -0,0 +1,48 @@
+package events
+
+import (
+	"time"
+
+	htmx "github.com/thisisthemurph/hx"
+	"github.com/thisisthemurph/hx/cmd/hxgen/testdata/events/models"
+)
+
+type Level string
+
+type Audit struct {
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type ItemAddedDetail struct {
+	Audit
+	ID       int              `json:"id"`
+	Name     string           `json:"name"`
+	Price    float64          `json:"price,omitempty"`
+	Tags     []string         `json:"tags"`
+	Meta     map[string]any   `json:"meta,omitempty"`
+	Parent   *ItemAddedDetail `json:"parent"`
+	Count    int64            `json:"count,string"`
+	Internal string           `json:"-"`
+	private  string
+	Untagged bool
+	Dashed   string `json:"data-value"`
+}
+
+type ToastDetail struct {
+	Level   Level         `json:"level"`
+	Message string        `json:"message"`
+	Timeout time.Duration `json:"timeout"`
+}
+
+var (
+	ItemAdded = htmx.DefineEvent[ItemAddedDetail]("item-added")
+	Toast     = htmx.DefineEvent[ToastDetail]("showMessage")
+	Removed   = htmx.DefineEvent[int]("item-removed")
+	Selected  = htmx.DefineEvent[models.Item]("item-selected")
+	Closed    = htmx.DefineEvent[*ToastDetail]("toast-closed")
+	Filtered  = htmx.DefineEvent[map[string][]models.Item]("filtered")
+	Inline    = htmx.DefineEvent[struct {
+		OK bool `json:"ok"`
+	}]("inline")
+)
@@ -0,0 +1,21 @@
+package models
+
+type Status int
+
+const (
+	StatusActive Status = iota
+	StatusArchived
+)
+
+func (s Status) MarshalText() ([]byte, error) {
+	if s == StatusArchived {
+		return []byte("archived"), nil
+	}
+	return []byte("active"), nil
+}
+
+type Item struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Status Status `json:"status"`
+}
@@ -0,0 +1,9 @@
+package nested
+
+import "github.com/thisisthemurph/hx"
+
+type ToastDetail struct {
+	Title string `json:"title"`
+}
+
+var Refreshed = hx.DefineEvent[[]ToastDetail]("refreshed")
@@ -0,0 +1,155 @@
+package hx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/thisisthemurph/hx/internal/hook"
+)
+
+// ErrNoTriggerCollector is returned by AddTrigger when the context has no trigger collector.
+var ErrNoTriggerCollector = errors.New("no trigger collector in context")
+
+type triggerCollectorKey struct{}
+
+// triggerCollector collects trigger events for each TriggerPhase until they are flushed into the headers.
+type triggerCollector struct {
+	mu      sync.Mutex
+	events  map[TriggerPhase][]TriggerEvent
+	flushed bool
+}
+
+// triggerPhases are the phases in the order their headers are written.
+var triggerPhases = []TriggerPhase{TriggerPhaseImmediate, TriggerPhaseAfterSwap, TriggerPhaseAfterSettle}
+
+// WithTriggerCollector returns a copy of ctx with a new trigger collector, to which events can be
+// added using AddTrigger and later written to the response using FlushTriggers.
+//
+// Most applications should use the CollectTriggers middleware rather than calling this directly.
+func WithTriggerCollector(ctx context.Context) context.Context {
+	return context.WithValue(ctx, triggerCollectorKey{}, &triggerCollector{events: make(map[TriggerPhase][]TriggerEvent)})
+}
+
+// AddTrigger adds trigger events to the collector in the given context, to be written to the trigger
+// header of the given phase when the response headers are written. This allows middleware, handlers
+// and services without access to the http.ResponseWriter to trigger events.
+//
+// The event names and detail are validated, and the detail is encoded as JSON, when the events are
+// added, so that any error is returned to the caller. Later changes to the detail have no effect.
+//
+// Parameters:
+//
+//	ctx (context.Context): A context containing a trigger collector, such as the request context within CollectTriggers.
+//	phase (TriggerPhase): When the events are triggered on the client.
+//	events (...TriggerEvent): The events to trigger.
+//
+// Returns:
+//
+//	error: ErrNoTriggerCollector if the context has no collector, ErrHeadersAlreadyWritten if the
+//	collected events have already been written, or an error if an event is invalid.
+//
+// Example usage:
+//
+//	func (s *CartService) Add(ctx context.Context, item Item) error {
+//		// ...
+//		return hx.AddTrigger(ctx, hx.TriggerPhaseImmediate, hx.NewTriggerEvent("cartUpdated", item))
+//	}
+func AddTrigger(ctx context.Context, phase TriggerPhase, events ...TriggerEvent) error {
+	c, ok := ctx.Value(triggerCollectorKey{}).(*triggerCollector)
+	if !ok {
+		return ErrNoTriggerCollector
+	}
+
+	encoded := make([]TriggerEvent, 0, len(events))
+	for _, event := range events {
+		name, err := normalizeEventName(event.Name)
+		if err != nil {
+			return err
+		}
+		event.Name = name
+		detail, err := event.detail()
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(detail)
+		if err != nil {
+			return err
+		}
+		encoded = append(encoded, TriggerEvent{Name: event.Name, Detail: json.RawMessage(data)})
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.flushed {
+		return ErrHeadersAlreadyWritten
+	}
+	c.events[phase] = append(c.events[phase], encoded...)
+	return nil
+}
+
+// FlushTriggers writes the events collected in the given context to the trigger headers of w, merging
+// them with any events already present. Events with the same name in a phase keep the last detail.
+//
+// After FlushTriggers has been called, AddTrigger returns ErrHeadersAlreadyWritten for the context.
+// FlushTriggers does nothing if the context has no trigger collector.
+//
+// Most applications should use the CollectTriggers middleware, which calls FlushTriggers before the
+// response headers are written.
+func FlushTriggers(ctx context.Context, w HeaderResponseWriter) error {
+	c, ok := ctx.Value(triggerCollectorKey{}).(*triggerCollector)
+	if !ok {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.flushed {
+		return nil
+	}
+	c.flushed = true
+
+	decorators := make([]HeaderDecorator, 0, len(triggerPhases))
+	for _, phase := range triggerPhases {
+		if events := c.events[phase]; len(events) > 0 {
+			decorators = append(decorators, triggerWithDetail(phase.Header(), DuplicateKeepLast, events...))
+		}
+	}
+	c.events = nil
+	return SetHeadersAtomic(w, decorators...)
+}
+
+// CollectTriggers is a middleware function adding a trigger collector to the request context, so that
+// events can be added using AddTrigger. The collected events are written to the HX-Trigger,
+// HX-Trigger-After-Swap and HX-Trigger-After-Settle headers just before the response headers are written.
+//
+// If the collected events cannot be merged into the headers, for example because a handler set a
+// malformed trigger header, the headers are left unchanged. Use FlushTriggers directly to handle the error.
+//
+// If the request context already has a trigger collector, the request is passed through unchanged.
+// Otherwise the response writer passed to next implements http.Flusher and http.Hijacker only if
+// w does, so WebSocket upgrades are unaffected.
+//
+// Example usage:
+//
+//	mux := http.NewServeMux()
+//	mux.HandleFunc("POST /cart", func(w http.ResponseWriter, r *http.Request) {
+//		_ = hx.AddTrigger(r.Context(), hx.TriggerPhaseAfterSwap, hx.NewTriggerEvent("cartUpdated", nil))
+//		w.WriteHeader(http.StatusNoContent)
+//	})
+//	http.ListenAndServe(":8080", hx.CollectTriggers(mux))
+func CollectTriggers(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Value(triggerCollectorKey{}).(*triggerCollector); ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := WithTriggerCollector(r.Context())
+		flush := func() { _ = FlushTriggers(ctx, w) }
+		next.ServeHTTP(hook.Wrap(&hook.Writer{ResponseWriter: w, Before: flush}), r.WithContext(ctx))
+		flush()
+	})
+}
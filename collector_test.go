@@ -0,0 +1,132 @@
+package hx_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thisisthemurph/hx"
+)
+
+func TestCollectTriggers(t *testing.T) {
+	testCases := []struct {
+		name            string
+		handler         http.HandlerFunc
+		expectedHeaders map[string]string
+	}{
+		{
+			name: "flushes on write header",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_ = hx.AddTrigger(r.Context(), hx.TriggerPhaseImmediate, hx.NewTriggerEvent("event1", nil))
+				_ = hx.AddTrigger(r.Context(), hx.TriggerPhaseAfterSwap, hx.NewTriggerEvent("event2", "detail"))
+				_ = hx.AddTrigger(r.Context(), hx.TriggerPhaseAfterSettle, hx.NewTargetedTriggerEvent("event3", "#target", nil))
+				w.WriteHeader(http.StatusNoContent)
+			},
+			expectedHeaders: map[string]string{
+				hx.HeaderTrigger:            `{"event1":null}`,
+				hx.HeaderTriggerAfterSwap:   `{"event2":"detail"}`,
+				hx.HeaderTriggerAfterSettle: `{"event3":{"target":"#target"}}`,
+			},
+		}, {
+			name: "flushes on write",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_ = hx.AddTrigger(r.Context(), hx.TriggerPhaseImmediate, hx.NewTriggerEvent("event1", nil))
+				_, _ = w.Write([]byte("hello"))
+			},
+			expectedHeaders: map[string]string{hx.HeaderTrigger: `{"event1":null}`},
+		}, {
+			name: "flushes when handler writes nothing",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_ = hx.AddTrigger(r.Context(), hx.TriggerPhaseImmediate, hx.NewTriggerEvent("event1", nil))
+			},
+			expectedHeaders: map[string]string{hx.HeaderTrigger: `{"event1":null}`},
+		}, {
+			name: "merges with headers set by the handler",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_ = hx.AddTrigger(r.Context(), hx.TriggerPhaseImmediate, hx.NewTriggerEvent("event2", 2), hx.NewTriggerEvent("event1", 1))
+				_ = hx.SetHeaders(w, hx.Trigger("event1"))
+				w.WriteHeader(http.StatusOK)
+			},
+			expectedHeaders: map[string]string{hx.HeaderTrigger: `{"event1":1,"event2":2}`},
+		}, {
+			name: "later events keep the last detail",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_ = hx.AddTrigger(r.Context(), hx.TriggerPhaseImmediate, hx.NewTriggerEvent("event1", 1))
+				_ = hx.AddTrigger(r.Context(), hx.TriggerPhaseImmediate, hx.NewTriggerEvent("event1", 2))
+			},
+			expectedHeaders: map[string]string{hx.HeaderTrigger: `{"event1":2}`},
+		}, {
+			name:            "no events",
+			handler:         func(w http.ResponseWriter, r *http.Request) {},
+			expectedHeaders: map[string]string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+			hx.CollectTriggers(tc.handler).ServeHTTP(w, r)
+
+			res := w.Result()
+			for _, header := range []string{hx.HeaderTrigger, hx.HeaderTriggerAfterSwap, hx.HeaderTriggerAfterSettle} {
+				assert.Equal(t, tc.expectedHeaders[header], res.Header.Get(header), header)
+			}
+		})
+	}
+}
+
+func TestAddTrigger_CopiesDetail(t *testing.T) {
+	detail := map[string]int{"count": 1}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = hx.AddTrigger(r.Context(), hx.TriggerPhaseImmediate, hx.NewTriggerEvent("event", detail))
+		detail["count"] = 2
+	})
+
+	w := httptest.NewRecorder()
+	hx.CollectTriggers(handler).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, `{"event":{"count":1}}`, w.Header().Get(hx.HeaderTrigger))
+}
+
+func TestAddTrigger_Errors(t *testing.T) {
+	ctx := hx.WithTriggerCollector(context.Background())
+
+	assert.ErrorIs(t, hx.AddTrigger(context.Background(), hx.TriggerPhaseImmediate, hx.NewTriggerEvent("event", nil)), hx.ErrNoTriggerCollector)
+	assert.ErrorIs(t, hx.AddTrigger(ctx, hx.TriggerPhaseImmediate, hx.NewTriggerEvent(" ", nil)), hx.ErrInvalidEventName)
+	assert.Error(t, hx.AddTrigger(ctx, hx.TriggerPhaseImmediate, hx.NewTriggerEvent("event", make(chan int))))
+
+	w := httptest.NewRecorder()
+	assert.NoError(t, hx.FlushTriggers(ctx, w))
+	assert.Empty(t, w.Header())
+	assert.True(t, errors.Is(hx.AddTrigger(ctx, hx.TriggerPhaseImmediate, hx.NewTriggerEvent("event", nil)), hx.ErrHeadersAlreadyWritten))
+}
+
+func TestCollectTriggers_Nested(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = hx.AddTrigger(r.Context(), hx.TriggerPhaseImmediate, hx.NewTriggerEvent("event", nil))
+	})
+
+	w := httptest.NewRecorder()
+	hx.CollectTriggers(hx.CollectTriggers(handler)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, `{"event":null}`, w.Header().Get(hx.HeaderTrigger))
+}
+
+func TestCollectTriggers_KeepsHijacker(t *testing.T) {
+	rec := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+	handler := hx.CollectTriggers(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hijacker, ok := w.(http.Hijacker)
+		if assert.True(t, ok) {
+			_, _, _ = hijacker.Hijack()
+		}
+	}))
+
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.True(t, rec.hijacked)
+}
@@ -0,0 +1,69 @@
+package hx
+
+// Event is a trigger event definition with a name and a detail of type T.
+//
+// Defining events once, with the type of their detail, ensures that event names and payloads are
+// consistent across handlers and checked by the compiler.
+//
+// Example usage:
+//
+//	type ItemAddedDetail struct {
+//		ID   int    `json:"id"`
+//		Name string `json:"name"`
+//	}
+//
+//	var ItemAdded = hx.DefineEvent[ItemAddedDetail]("item-added")
+//
+//	err := hx.SetHeaders(w, ItemAdded.Trigger(ItemAddedDetail{ID: 1, Name: "Widget"}))
+type Event[T any] struct {
+	name string
+}
+
+// DefineEvent defines a trigger event with the given name and a detail of type T.
+//
+// DefineEvent panics if the name is not a valid event name, as determined by ValidateEventName.
+// It is intended to be used when initializing package level variables.
+func DefineEvent[T any](name string) Event[T] {
+	if err := ValidateEventName(name); err != nil {
+		panic(err)
+	}
+	return Event[T]{name: name}
+}
+
+// Name returns the name of the event.
+func (e Event[T]) Name() string {
+	return e.name
+}
+
+// New creates a new TriggerEvent for the event with the given detail.
+func (e Event[T]) New(detail T) TriggerEvent {
+	return NewTriggerEvent(e.name, detail)
+}
+
+// NewTargeted creates a new TriggerEvent for the event with the given detail, dispatched on
+// the element matching the target CSS selector.
+func (e Event[T]) NewTargeted(target string, detail T) TriggerEvent {
+	return NewTargetedTriggerEvent(e.name, target, detail)
+}
+
+// Trigger adds the event with the given detail to the HX-Trigger header. See TriggerWithDetail.
+func (e Event[T]) Trigger(detail T) HeaderDecorator {
+	return TriggerWithDetail(e.New(detail))
+}
+
+// TriggerAfterSwap adds the event with the given detail to the HX-Trigger-After-Swap header.
+// See TriggerAfterSwapWithDetail.
+func (e Event[T]) TriggerAfterSwap(detail T) HeaderDecorator {
+	return TriggerAfterSwapWithDetail(e.New(detail))
+}
+
+// TriggerAfterSettle adds the event with the given detail to the HX-Trigger-After-Settle header.
+// See TriggerAfterSettleWithDetail.
+func (e Event[T]) TriggerAfterSettle(detail T) HeaderDecorator {
+	return TriggerAfterSettleWithDetail(e.New(detail))
+}
+
+// TriggerPhase adds the event with the given detail to the trigger header of the given phase.
+func (e Event[T]) TriggerPhase(phase TriggerPhase, detail T) HeaderDecorator {
+	return triggerWithDetail(phase.Header(), DuplicateKeepLast, e.New(detail))
+}
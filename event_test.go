@@ -0,0 +1,63 @@
+package hx_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thisisthemurph/hx"
+)
+
+type itemAddedDetail struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+var itemAdded = hx.DefineEvent[itemAddedDetail]("item-added")
+
+func TestEvent_Trigger(t *testing.T) {
+	detail := itemAddedDetail{ID: 1, Name: "Widget"}
+
+	testCases := []struct {
+		header    string
+		decorator hx.HeaderDecorator
+	}{
+		{header: hx.HeaderTrigger, decorator: itemAdded.Trigger(detail)},
+		{header: hx.HeaderTriggerAfterSwap, decorator: itemAdded.TriggerAfterSwap(detail)},
+		{header: hx.HeaderTriggerAfterSettle, decorator: itemAdded.TriggerAfterSettle(detail)},
+		{header: hx.HeaderTriggerAfterSettle, decorator: itemAdded.TriggerPhase(hx.TriggerPhaseAfterSettle, detail)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.header, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			err := hx.SetHeaders(w, tc.decorator)
+
+			assert.NoError(t, err)
+			assert.Equal(t, `{"item-added":{"id":1,"name":"Widget"}}`, w.Header().Get(tc.header))
+		})
+	}
+}
+
+func TestEvent_New(t *testing.T) {
+	event := itemAdded.New(itemAddedDetail{ID: 2})
+
+	assert.Equal(t, "item-added", itemAdded.Name())
+	assert.Equal(t, hx.NewTriggerEvent("item-added", itemAddedDetail{ID: 2}), event)
+}
+
+func TestEvent_NewTargeted(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	err := hx.SetHeaders(w, hx.TriggerWithDetail(itemAdded.NewTargeted("#cart", itemAddedDetail{ID: 3, Name: "Gadget"})))
+
+	assert.NoError(t, err)
+	assert.Equal(t, `{"item-added":{"id":3,"name":"Gadget","target":"#cart"}}`, w.Header().Get(hx.HeaderTrigger))
+}
+
+func TestDefineEvent_PanicsForInvalidName(t *testing.T) {
+	assert.Panics(t, func() {
+		hx.DefineEvent[string]("")
+	})
+}
@@ -0,0 +1,227 @@
+// Package flash sends flash messages, such as toast notifications, to the client.
+//
+// For HTMX requests the message is sent as the detail of an event in the HX-Trigger header, to be
+// displayed by a listener on the page. For full page requests, and responses which navigate away from
+// the page, the message is stored in a cookie and read with Pop when the next page is rendered. This
+// allows the same API to be used for boosted and full page navigations.
+//
+// Example usage:
+//
+//	func save(w http.ResponseWriter, r *http.Request) {
+//		// ...
+//		_ = flash.Send(w, r, flash.Success("Saved", "Your changes have been saved."))
+//		w.WriteHeader(http.StatusNoContent)
+//	}
+//
+// The message is received on the client as a showMessage event:
+//
+//	document.body.addEventListener("showMessage", (e) => showToast(e.detail));
+package flash
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/thisisthemurph/hx"
+	"github.com/thisisthemurph/hx/middleware"
+)
+
+const (
+	DefaultEventName  = "showMessage" // The default name of the event triggered for flash messages.
+	DefaultCookieName = "hx-flash"    // The default name of the cookie storing flash messages.
+)
+
+// Level is the severity of a flash message.
+type Level string
+
+const (
+	LevelInfo    Level = "info"
+	LevelSuccess Level = "success"
+	LevelWarning Level = "warning"
+	LevelError   Level = "error"
+)
+
+// Flash is a message to be displayed to the user.
+//
+// Flash is encoded as JSON with the keys level, title, message and timeout, where the timeout is
+// given in milliseconds. The title and timeout are omitted when empty.
+type Flash struct {
+	Level   Level         // The severity of the message.
+	Title   string        // An optional title for the message.
+	Message string        // The message to display.
+	Timeout time.Duration // How long the message is displayed; zero leaves this to the client.
+}
+
+// Info creates a Flash with LevelInfo.
+func Info(title, message string) Flash {
+	return Flash{Level: LevelInfo, Title: title, Message: message}
+}
+
+// Success creates a Flash with LevelSuccess.
+func Success(title, message string) Flash {
+	return Flash{Level: LevelSuccess, Title: title, Message: message}
+}
+
+// Warning creates a Flash with LevelWarning.
+func Warning(title, message string) Flash {
+	return Flash{Level: LevelWarning, Title: title, Message: message}
+}
+
+// Error creates a Flash with LevelError.
+func Error(title, message string) Flash {
+	return Flash{Level: LevelError, Title: title, Message: message}
+}
+
+// WithTimeout returns a copy of the Flash displayed for the given duration.
+func (f Flash) WithTimeout(timeout time.Duration) Flash {
+	f.Timeout = timeout
+	return f
+}
+
+type flashJSON struct {
+	Level   Level  `json:"level"`
+	Title   string `json:"title,omitempty"`
+	Message string `json:"message"`
+	Timeout int64  `json:"timeout,omitempty"`
+}
+
+// MarshalJSON encodes the Flash as JSON, with the timeout in milliseconds.
+func (f Flash) MarshalJSON() ([]byte, error) {
+	return json.Marshal(flashJSON{
+		Level:   f.Level,
+		Title:   f.Title,
+		Message: f.Message,
+		Timeout: f.Timeout.Milliseconds(),
+	})
+}
+
+// UnmarshalJSON decodes the Flash from JSON, with the timeout in milliseconds.
+func (f *Flash) UnmarshalJSON(data []byte) error {
+	var v flashJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*f = Flash{
+		Level:   v.Level,
+		Title:   v.Title,
+		Message: v.Message,
+		Timeout: time.Duration(v.Timeout) * time.Millisecond,
+	}
+	return nil
+}
+
+// Flasher sends flash messages using the configured event and cookie names.
+// The zero value uses DefaultEventName and DefaultCookieName.
+type Flasher struct {
+	EventName  string // The name of the event triggered for HTMX requests.
+	CookieName string // The name of the cookie storing messages for other requests.
+	CookiePath string // The path of the cookie; defaults to "/".
+	Secure     bool   // Whether the cookie is only sent over HTTPS.
+}
+
+// DefaultFlasher is the Flasher used by Send and Pop.
+var DefaultFlasher = &Flasher{}
+
+// Send sends the flash message using DefaultFlasher. See Flasher.Send.
+func Send(w http.ResponseWriter, r *http.Request, f Flash) error {
+	return DefaultFlasher.Send(w, r, f)
+}
+
+// Pop reads and clears the flash message using DefaultFlasher. See Flasher.Pop.
+func Pop(w http.ResponseWriter, r *http.Request) (Flash, bool) {
+	return DefaultFlasher.Pop(w, r)
+}
+
+// Send sends the flash message to the client.
+//
+// If the request is a HTMX request, and the response does not load a new page using HX-Redirect or
+// HX-Refresh, the message is sent as the detail of the configured event
+// in the HX-Trigger header. Otherwise, including for history restore requests, the message is stored
+// in a cookie to be read with Pop when the next page is rendered. Send must therefore be called after
+// any navigation headers are set, and before the response headers are written.
+//
+// Only one message is stored at a time; sending another message replaces the previous one.
+//
+// Example usage:
+//
+//	_ = hx.SetHeaders(w, hx.Redirect("/items"))
+//	_ = flash.Send(w, r, flash.Success("", "Item created."))
+func (fl *Flasher) Send(w http.ResponseWriter, r *http.Request, f Flash) error {
+	if middleware.FromRequest(r).IsPartial() && !navigates(w.Header()) {
+		return hx.SetHeaders(w, hx.TriggerWithDetail(hx.NewTriggerEvent(fl.eventName(), f)))
+	}
+
+	data, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, fl.cookie(base64.RawURLEncoding.EncodeToString(data), 0))
+	return nil
+}
+
+// Pop reads the flash message stored in the cookie by Send and clears the cookie, so that the message
+// is only displayed once. It reports false if there is no message, or the cookie cannot be decoded.
+//
+// Pop is intended to be called when rendering a full page, before the response headers are written.
+//
+// Example usage:
+//
+//	if f, ok := flash.Pop(w, r); ok {
+//		data.Flash = &f
+//	}
+func (fl *Flasher) Pop(w http.ResponseWriter, r *http.Request) (Flash, bool) {
+	c, err := r.Cookie(fl.cookieName())
+	if err != nil {
+		return Flash{}, false
+	}
+	http.SetCookie(w, fl.cookie("", -1))
+
+	data, err := base64.RawURLEncoding.DecodeString(c.Value)
+	if err != nil {
+		return Flash{}, false
+	}
+	var f Flash
+	if err := json.Unmarshal(data, &f); err != nil {
+		return Flash{}, false
+	}
+	return f, true
+}
+
+func (fl *Flasher) eventName() string {
+	if fl.EventName == "" {
+		return DefaultEventName
+	}
+	return fl.EventName
+}
+
+func (fl *Flasher) cookieName() string {
+	if fl.CookieName == "" {
+		return DefaultCookieName
+	}
+	return fl.CookieName
+}
+
+func (fl *Flasher) cookie(value string, maxAge int) *http.Cookie {
+	path := fl.CookiePath
+	if path == "" {
+		path = "/"
+	}
+	return &http.Cookie{
+		Name:     fl.cookieName(),
+		Value:    value,
+		Path:     path,
+		MaxAge:   maxAge,
+		Secure:   fl.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// navigates reports whether the response headers cause the client to load a new page.
+// HX-Location is not included as the new content is loaded without a full page load, and the
+// event is triggered before the content is swapped.
+func navigates(h http.Header) bool {
+	return h.Get(hx.HeaderRedirect) != "" || h.Get(hx.HeaderRefresh) == "true"
+}
@@ -0,0 +1,155 @@
+package flash_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thisisthemurph/hx"
+	"github.com/thisisthemurph/hx/flash"
+	"github.com/thisisthemurph/hx/middleware"
+)
+
+func TestFlash_JSON(t *testing.T) {
+	f := flash.Success("Saved", "Your changes have been saved.").WithTimeout(3 * time.Second)
+
+	data, err := json.Marshal(f)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"level":"success","title":"Saved","message":"Your changes have been saved.","timeout":3000}`, string(data))
+
+	var decoded flash.Flash
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, f, decoded)
+
+	data, err = json.Marshal(flash.Info("", "Hello"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"level":"info","message":"Hello"}`, string(data))
+}
+
+func TestSend(t *testing.T) {
+	testCases := []struct {
+		name            string
+		requestHeaders  map[string]string
+		decorators      []hx.HeaderDecorator
+		withMiddleware  bool
+		expectedTrigger bool
+	}{
+		{
+			name:            "htmx request",
+			requestHeaders:  map[string]string{"HX-Request": "true"},
+			expectedTrigger: true,
+		}, {
+			name:            "htmx request with middleware",
+			requestHeaders:  map[string]string{"HX-Request": "true", "HX-Boosted": "true"},
+			withMiddleware:  true,
+			expectedTrigger: true,
+		}, {
+			name:            "htmx request with location",
+			requestHeaders:  map[string]string{"HX-Request": "true"},
+			decorators:      []hx.HeaderDecorator{hx.Location("/items")},
+			expectedTrigger: true,
+		}, {
+			name: "full page request",
+		}, {
+			name:           "htmx request with redirect",
+			requestHeaders: map[string]string{"HX-Request": "true"},
+			decorators:     []hx.HeaderDecorator{hx.Redirect("/items")},
+		}, {
+			name:           "htmx request with refresh",
+			requestHeaders: map[string]string{"HX-Request": "true"},
+			decorators:     []hx.HeaderDecorator{hx.Refresh()},
+		}, {
+			name:           "history restore request",
+			requestHeaders: map[string]string{"HX-Request": "true", "HX-History-Restore-Request": "true"},
+			withMiddleware: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/items", nil)
+			for key, value := range tc.requestHeaders {
+				r.Header.Set(key, value)
+			}
+			w := httptest.NewRecorder()
+
+			var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.NoError(t, hx.SetHeaders(w, tc.decorators...))
+				assert.NoError(t, flash.Send(w, r, flash.Error("Failed", "Something went wrong.")))
+			})
+			if tc.withMiddleware {
+				handler = middleware.WithHTMX(handler)
+			}
+			handler.ServeHTTP(w, r)
+
+			res := w.Result()
+			if tc.expectedTrigger {
+				assert.JSONEq(t, `{"showMessage":{"level":"error","title":"Failed","message":"Something went wrong."}}`, res.Header.Get(hx.HeaderTrigger))
+				assert.Empty(t, res.Cookies())
+				return
+			}
+
+			assert.Empty(t, res.Header.Get(hx.HeaderTrigger))
+			if assert.Len(t, res.Cookies(), 1) {
+				assert.Equal(t, flash.DefaultCookieName, res.Cookies()[0].Name)
+				assert.True(t, res.Cookies()[0].HttpOnly)
+			}
+		})
+	}
+}
+
+func TestFlasher_CustomEventName(t *testing.T) {
+	fl := &flash.Flasher{EventName: "toast"}
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	r.Header.Set("HX-Request", "true")
+	w := httptest.NewRecorder()
+
+	assert.NoError(t, fl.Send(w, r, flash.Info("", "Hello")))
+	assert.Equal(t, `{"toast":{"level":"info","message":"Hello"}}`, w.Header().Get(hx.HeaderTrigger))
+}
+
+func TestPop(t *testing.T) {
+	sent := flash.Warning("Careful", "Check your input.").WithTimeout(time.Second)
+
+	w := httptest.NewRecorder()
+	assert.NoError(t, flash.Send(w, httptest.NewRequest(http.MethodPost, "/", nil), sent))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, c := range w.Result().Cookies() {
+		r.AddCookie(c)
+	}
+	w = httptest.NewRecorder()
+
+	f, ok := flash.Pop(w, r)
+	assert.True(t, ok)
+	assert.Equal(t, sent, f)
+	if cookies := w.Result().Cookies(); assert.Len(t, cookies, 1) {
+		assert.Equal(t, flash.DefaultCookieName, cookies[0].Name)
+		assert.Equal(t, -1, cookies[0].MaxAge)
+	}
+}
+
+func TestPop_NoFlash(t *testing.T) {
+	testCases := []struct {
+		name   string
+		cookie *http.Cookie
+	}{
+		{name: "no cookie"},
+		{name: "malformed cookie", cookie: &http.Cookie{Name: flash.DefaultCookieName, Value: "not-base64!"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.cookie != nil {
+				r.AddCookie(tc.cookie)
+			}
+
+			_, ok := flash.Pop(httptest.NewRecorder(), r)
+			assert.False(t, ok)
+		})
+	}
+}
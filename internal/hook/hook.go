@@ -0,0 +1,131 @@
+// Package hook provides a http.ResponseWriter wrapper calling a function just before the response
+// headers are written, shared by the middleware wrapping response writers in this module.
+package hook
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+)
+
+// WritesHeaders reports whether WriteHeader with the status code writes the response headers.
+// Informational (1xx) status codes, other than 101 Switching Protocols, do not, as further
+// headers may still be sent.
+func WritesHeaders(statusCode int) bool {
+	return statusCode >= 200 || statusCode == http.StatusSwitchingProtocols
+}
+
+// ResponseWriter is a http.ResponseWriter wrapper with every optional interface forwarded by Wrap.
+type ResponseWriter interface {
+	http.ResponseWriter
+	http.Flusher
+	http.Hijacker
+	Unwrap() http.ResponseWriter
+}
+
+// Writer wraps a http.ResponseWriter, calling Before each time the response headers may be about
+// to be written. Before must therefore be safe to call more than once.
+//
+// Writer has Flush and Hijack methods whether or not the underlying writer supports them; pass it
+// to Wrap before handing it to a handler.
+type Writer struct {
+	http.ResponseWriter
+	Before func()
+}
+
+// WriteHeader calls Before and sends the HTTP response header with the provided status code.
+func (w *Writer) WriteHeader(statusCode int) {
+	if WritesHeaders(statusCode) {
+		w.Before()
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write calls Before and writes the data to the connection as part of the HTTP reply.
+func (w *Writer) Write(b []byte) (int, error) {
+	w.Before()
+	return w.ResponseWriter.Write(b)
+}
+
+// ReadFrom calls Before and copies the data from the reader to the connection, using the
+// io.ReaderFrom implementation of the underlying writer if it has one.
+func (w *Writer) ReadFrom(r io.Reader) (int64, error) {
+	w.Before()
+	return readFrom(w.ResponseWriter, r)
+}
+
+// Flush calls Before and sends any buffered data to the client, if supported.
+func (w *Writer) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.Before()
+		f.Flush()
+	}
+}
+
+// Hijack lets the caller take over the connection, returning http.ErrNotSupported if the
+// underlying writer does not support it.
+func (w *Writer) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	return h.Hijack()
+}
+
+// Unwrap returns the underlying http.ResponseWriter, allowing use with http.ResponseController.
+func (w *Writer) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Wrap returns a http.ResponseWriter forwarding to w which implements http.Flusher and http.Hijacker
+// only if the writer returned by w.Unwrap does, so that type assertions made by handlers behave as
+// they would without w. The result always implements io.ReaderFrom, and its Unwrap method returns w.
+func Wrap(w ResponseWriter) http.ResponseWriter {
+	base := w.Unwrap()
+	_, flusher := base.(http.Flusher)
+	_, hijacker := base.(http.Hijacker)
+
+	switch {
+	case flusher && hijacker:
+		return flushHijackWriter{writer{w}}
+	case flusher:
+		return flushWriter{writer{w}}
+	case hijacker:
+		return hijackWriter{writer{w}}
+	default:
+		return writer{w}
+	}
+}
+
+// writer exposes only the methods of http.ResponseWriter, io.ReaderFrom and Unwrap.
+type writer struct {
+	w ResponseWriter
+}
+
+func (w writer) Header() http.Header                 { return w.w.Header() }
+func (w writer) Write(b []byte) (int, error)         { return w.w.Write(b) }
+func (w writer) WriteHeader(statusCode int)          { w.w.WriteHeader(statusCode) }
+func (w writer) ReadFrom(r io.Reader) (int64, error) { return readFrom(w.w, r) }
+func (w writer) Unwrap() http.ResponseWriter         { return w.w }
+
+type flushWriter struct{ writer }
+
+func (w flushWriter) Flush() { w.w.Flush() }
+
+type hijackWriter struct{ writer }
+
+func (w hijackWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) { return w.w.Hijack() }
+
+type flushHijackWriter struct{ writer }
+
+func (w flushHijackWriter) Flush()                                       { w.w.Flush() }
+func (w flushHijackWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) { return w.w.Hijack() }
+
+// readFrom copies from the reader to w, using its io.ReaderFrom implementation if it has one.
+func readFrom(w io.Writer, r io.Reader) (int64, error) {
+	if rf, ok := w.(io.ReaderFrom); ok {
+		return rf.ReadFrom(r)
+	}
+	return io.Copy(w, r)
+}
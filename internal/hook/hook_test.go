@@ -0,0 +1,136 @@
+package hook_test
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thisisthemurph/hx/internal/hook"
+)
+
+func TestWriter_CallsBeforeWhenHeadersAreWritten(t *testing.T) {
+	testCases := []struct {
+		name          string
+		write         func(w http.ResponseWriter)
+		expectedCalls int
+	}{
+		{
+			name:          "WriteHeader",
+			write:         func(w http.ResponseWriter) { w.WriteHeader(http.StatusOK) },
+			expectedCalls: 1,
+		}, {
+			name:          "WriteHeader switching protocols",
+			write:         func(w http.ResponseWriter) { w.WriteHeader(http.StatusSwitchingProtocols) },
+			expectedCalls: 1,
+		}, {
+			name:          "WriteHeader informational",
+			write:         func(w http.ResponseWriter) { w.WriteHeader(http.StatusEarlyHints) },
+			expectedCalls: 0,
+		}, {
+			name:          "Write",
+			write:         func(w http.ResponseWriter) { _, _ = w.Write([]byte("hello")) },
+			expectedCalls: 1,
+		}, {
+			name:          "Flush",
+			write:         func(w http.ResponseWriter) { w.(http.Flusher).Flush() },
+			expectedCalls: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			w := &hook.Writer{ResponseWriter: httptest.NewRecorder(), Before: func() { calls++ }}
+
+			tc.write(w)
+
+			assert.Equal(t, tc.expectedCalls, calls)
+		})
+	}
+}
+
+// hijackRecorder is a httptest.ResponseRecorder implementing http.Hijacker.
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (r hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, nil
+}
+
+// hijackOnly implements http.Hijacker but not http.Flusher.
+type hijackOnly struct {
+	http.ResponseWriter
+}
+
+func (hijackOnly) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, nil
+}
+
+func TestWrap_KeepsOptionalInterfaces(t *testing.T) {
+	testCases := []struct {
+		name             string
+		base             http.ResponseWriter
+		expectedFlusher  bool
+		expectedHijacker bool
+	}{
+		{
+			name:            "flusher",
+			base:            httptest.NewRecorder(),
+			expectedFlusher: true,
+		}, {
+			name:             "hijacker",
+			base:             hijackOnly{httptest.NewRecorder()},
+			expectedHijacker: true,
+		}, {
+			name:             "flusher and hijacker",
+			base:             hijackRecorder{httptest.NewRecorder()},
+			expectedFlusher:  true,
+			expectedHijacker: true,
+		}, {
+			name: "neither",
+			base: struct{ http.ResponseWriter }{httptest.NewRecorder()},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hw := &hook.Writer{ResponseWriter: tc.base, Before: func() {}}
+			w := hook.Wrap(hw)
+
+			_, isFlusher := w.(http.Flusher)
+			_, isHijacker := w.(http.Hijacker)
+			_, isReaderFrom := w.(io.ReaderFrom)
+			assert.Equal(t, tc.expectedFlusher, isFlusher)
+			assert.Equal(t, tc.expectedHijacker, isHijacker)
+			assert.True(t, isReaderFrom)
+			assert.Equal(t, http.ResponseWriter(hw), w.(interface{ Unwrap() http.ResponseWriter }).Unwrap())
+		})
+	}
+}
+
+func TestWrap_ReadFromCallsBefore(t *testing.T) {
+	rec := httptest.NewRecorder()
+	calls := 0
+	w := hook.Wrap(&hook.Writer{ResponseWriter: rec, Before: func() { calls++ }})
+
+	n, err := w.(io.ReaderFrom).ReadFrom(strings.NewReader("hello"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), n)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "hello", rec.Body.String())
+}
+
+func TestWriter_HijackNotSupported(t *testing.T) {
+	w := &hook.Writer{ResponseWriter: httptest.NewRecorder(), Before: func() {}}
+
+	_, _, err := w.Hijack()
+
+	assert.ErrorIs(t, err, http.ErrNotSupported)
+}
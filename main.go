@@ -2,8 +2,12 @@ package hx
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
 	"strings"
+	"unicode"
 )
 
 type HeaderResponseWriter interface {
@@ -38,10 +42,17 @@ type HeaderDecorator func(w HeaderResponseWriter) error
 //
 //	If an error is returned, the function will not add any of the remaining headers, but will leave all
 //	previously set headers, it is your responsibility to remove these headers.
+//	Use SetHeadersAtomic if the headers should instead be restored when an error occurs.
 //
 //	The order of decorators matters. Headers set by decorators earlier in the slice may be overwritten
 //	by subsequent decorators.
+//
+//	If w is, or wraps, a ResponseWriter whose headers have already been written, ErrHeadersAlreadyWritten
+//	is returned and no headers are set.
 func SetHeaders(w HeaderResponseWriter, funcs ...HeaderDecorator) error {
+	if err := checkHeadersWritten(w); err != nil {
+		return err
+	}
 	for _, fn := range funcs {
 		if err := fn(w); err != nil {
 			return err
@@ -50,6 +61,34 @@ func SetHeaders(w HeaderResponseWriter, funcs ...HeaderDecorator) error {
 	return nil
 }
 
+// SetHeadersAtomic sets custom HTTP headers in the provided http.ResponseWriter, as with SetHeaders,
+// but restores the headers to their original state if any of the HeaderDecorators return an error.
+//
+// A snapshot of the response writer's header map is taken before any decorators are applied. If a
+// decorator fails, the header map is restored from the snapshot and the error is returned, ensuring
+// a failing decorator cannot leave the response with only some of the intended headers.
+//
+// Example usage:
+//
+//	event := hx.NewTriggerEvent("itemAdded", item)
+//	err := hx.SetHeadersAtomic(w, hx.Retarget("#items"), hx.TriggerWithDetail(event))
+//	if err != nil {
+//		// Neither the HX-Retarget nor the HX-Trigger header has been set.
+//	}
+func SetHeadersAtomic(w HeaderResponseWriter, funcs ...HeaderDecorator) error {
+	if err := checkHeadersWritten(w); err != nil {
+		return err
+	}
+	snapshot := w.Header().Clone()
+	for _, fn := range funcs {
+		if err := fn(w); err != nil {
+			replaceHeader(w.Header(), snapshot)
+			return err
+		}
+	}
+	return nil
+}
+
 // SetHeader returns a function for setting the header on the http.ResponseWriter.
 // The returned function will always return a nil error.
 func SetHeader(key, value string) HeaderDecorator {
@@ -59,6 +98,47 @@ func SetHeader(key, value string) HeaderDecorator {
 	}
 }
 
+// Unset returns a function for removing the header from the http.ResponseWriter.
+// The returned function will always return a nil error.
+//
+// Example usage:
+//
+//	_ := hx.SetHeaders(w, hx.Unset(hx.HeaderRetarget))
+func Unset(key string) HeaderDecorator {
+	return func(w HeaderResponseWriter) error {
+		w.Header().Del(key)
+		return nil
+	}
+}
+
+// responseHeaders are all HTMX response headers.
+var responseHeaders = []string{
+	HeaderLocation,
+	HeaderPushURL,
+	HeaderRedirect,
+	HeaderRefresh,
+	HeaderReplaceURL,
+	HeaderReswap,
+	HeaderRetarget,
+	HeaderReselect,
+	HeaderTrigger,
+	HeaderTriggerAfterSettle,
+	HeaderTriggerAfterSwap,
+}
+
+// ClearHTMXHeaders returns a function for removing all HTMX response headers from the http.ResponseWriter.
+// This is useful for overriding defaults set by earlier middleware. Other headers are left untouched.
+//
+// Never returns an error.
+func ClearHTMXHeaders() HeaderDecorator {
+	return func(w HeaderResponseWriter) error {
+		for _, header := range responseHeaders {
+			w.Header().Del(header)
+		}
+		return nil
+	}
+}
+
 // Location allows you to do a client-side redirect that does not do a full page reload.
 // https://htmx.org/headers/hx-location/
 //
@@ -67,6 +147,36 @@ func Location(location string) HeaderDecorator {
 	return SetHeader(HeaderLocation, location)
 }
 
+// LocationWithContext allows you to do a client-side redirect that does not do a full page reload,
+// providing a context object to control how the new content is requested and swapped in.
+// https://htmx.org/headers/hx-location/
+//
+// The returned function will return an error if the context has no path, has an invalid Swap or
+// SwapSpec, or cannot be serialized into JSON.
+//
+// Example usage:
+//
+//	err := hx.SetHeaders(w, hx.LocationWithContext(hx.LocationContext{
+//		Path:   "/messages",
+//		Target: "#messages",
+//		Swap:   hx.SwapOuterHTML,
+//	}))
+func LocationWithContext(ctx LocationContext) HeaderDecorator {
+	return func(w HeaderResponseWriter) error {
+		if ctx.Path == "" {
+			return errors.New("location context must include a path")
+		}
+
+		data, err := json.Marshal(ctx)
+		if err != nil {
+			return err
+		}
+
+		w.Header().Set(HeaderLocation, string(data))
+		return nil
+	}
+}
+
 // PushURL pushes a new url into the history stack.
 // https://htmx.org/headers/hx-push-url/
 func PushURL(url string) HeaderDecorator {
@@ -125,9 +235,35 @@ func Reselect(selector string) HeaderDecorator {
 // Reswap allows you to override how the response will be swapped.
 // https://htmx.org/reference/#response_headers
 //
-// Never returns an error.
+// The returned function will return an error wrapping ErrInvalidSwap if the swap is not
+// a built-in or registered swap style.
 func Reswap(swap Swap) HeaderDecorator {
-	return SetHeader(HeaderReswap, swap.String())
+	return func(w HeaderResponseWriter) error {
+		if err := swap.validate(); err != nil {
+			return err
+		}
+		return SetHeader(HeaderReswap, swap.String())(w)
+	}
+}
+
+// ReswapWithSpec allows you to override how the response will be swapped, including any
+// swap modifiers such as timing, scrolling and view transitions.
+// https://htmx.org/attributes/hx-swap/
+//
+// The returned function will return the error from SwapSpec.Validate if the spec is invalid,
+// which wraps ErrInvalidSwap if the style is not a built-in or registered swap style.
+//
+// Example usage:
+//
+//	spec := hx.NewSwapSpec(hx.SwapBeforeEnd).WithScroll("#messages", hx.ScrollBottom)
+//	err := hx.SetHeaders(w, hx.ReswapWithSpec(spec))
+func ReswapWithSpec(spec SwapSpec) HeaderDecorator {
+	return func(w HeaderResponseWriter) error {
+		if err := spec.Validate(); err != nil {
+			return err
+		}
+		return SetHeader(HeaderReswap, spec.String())(w)
+	}
 }
 
 // Retarget a CSS selector that overrides the target of the content update to
@@ -150,55 +286,82 @@ func Retarget(target string) HeaderDecorator {
 // If the header already includes values, these will be retained.
 // If the current header value is a JSON object, the new headers will be added as part of the JSON object,
 // rather than a list of comma separated event names; as with the TriggerWithDetail function.
+// The JSON object is also used when an event name contains a comma or whitespace, which cannot be
+// represented in the comma separated list.
+//
+// Events are kept in the order they were added. An event already present in the header is handled
+// according to the duplicate policy.
 //
-// The returned function will return an error if existing headers require events to be JSON encoded and marshalling fails.
+// The returned function will return an error if existing headers require events to be JSON encoded and marshalling fails,
+// if an event name is empty, or if an event is a duplicate and the policy is DuplicateError.
 //
 // Parameters:
 //
 //	header: string - Specifies which header should be used to trigger the event.
+//	policy: DuplicatePolicy - Specifies how events already present in the header are handled.
 //	eventNames: ...string - Uniquely named events to be triggered.
 //
 // Example usage:
 //
-//	err := hx.SetHeaders(hx.trigger(hx.HeaderTrigger, "myFirstEvent", "someOtherEvent"))
+//	err := hx.SetHeaders(hx.trigger(hx.HeaderTrigger, hx.DuplicateKeepLast, "myFirstEvent", "someOtherEvent"))
 //
 // Or passing a slice of events:
 //
 //	events := []string {"event1", "event2"}
-//	err := hx.SetHeaders(hx.trigger(hx.TriggerAfterSwap, events...))
+//	err := hx.SetHeaders(hx.trigger(hx.TriggerAfterSwap, hx.DuplicateKeepLast, events...))
 //
 // https://htmx.org/headers/hx-trigger/
-func trigger(header string, eventNames ...string) HeaderDecorator {
+func trigger(header string, policy DuplicatePolicy, eventNames ...string) HeaderDecorator {
 	return func(w HeaderResponseWriter) error {
-		// eventMap := make(map[string]string)
 		eventList := make([]string, 0)
 
+		requiresJSON := false
+		for _, eventName := range eventNames {
+			eventName, err := normalizeEventName(eventName)
+			if err != nil {
+				return err
+			}
+			if !isListEventName(eventName) {
+				requiresJSON = true
+			}
+		}
+
 		currentHeaderValues := w.Header().Get(header)
-		if currentHeaderValues != "" {
-			// If header has JSON data, the current event names must also be added as JSON.
-			// Default to using the TriggerWithDetail function.
-			var js interface{}
-			err := json.Unmarshal([]byte(currentHeaderValues), &js)
-			if err == nil {
-				events := make([]TriggerEvent, 0)
-				for _, event := range eventNames {
-					events = append(events, TriggerEvent{
-						Name:   event,
-						Detail: nil,
-					})
-				}
 
-				return triggerWithDetail(header, events...)(w)
+		// If header has JSON data, or an event name cannot be represented in a comma separated list,
+		// the event names must be added as JSON. Default to using the TriggerWithDetail function.
+		if requiresJSON || isJSONObject(currentHeaderValues) {
+			events := make([]TriggerEvent, 0)
+			for _, event := range eventNames {
+				events = append(events, TriggerEvent{
+					Name:   strings.TrimSpace(event),
+					Detail: nil,
+				})
 			}
 
-			// If the data is not JSON, we must maintain the data and append the new
-			for _, eventName := range strings.Split(currentHeaderValues, ",") {
-				eventList = append(eventList, strings.TrimSpace(eventName))
+			return triggerWithDetail(header, policy, events...)(w)
+		}
+
+		if currentHeaderValues != "" {
+			// If the data is not JSON, we must maintain the data and append the new.
+			// Empty entries are skipped, as they are by ParseResponseHeaders.
+			for _, ev := range strings.Split(currentHeaderValues, ",") {
+				if eventName := strings.TrimSpace(ev); eventName != "" {
+					eventList = append(eventList, eventName)
+				}
 			}
 		}
 
 		for _, eventName := range eventNames {
-			eventList = append(eventList, strings.TrimSpace(eventName))
+			eventName = strings.TrimSpace(eventName)
+			if slices.Contains(eventList, eventName) {
+				if policy == DuplicateError {
+					return fmt.Errorf("%w: %q", ErrDuplicateEvent, eventName)
+				}
+				// Without detail, keeping the first or last event results in the same header.
+				continue
+			}
+			eventList = append(eventList, eventName)
 		}
 
 		w.Header().Set(header, strings.Join(eventList, ", "))
@@ -214,8 +377,11 @@ func trigger(header string, eventNames ...string) HeaderDecorator {
 // If the HX-Trigger header already includes events, these will be retained.
 // If the header value is a list of comma separated strings, these will be converted to
 // JSON objects with null detail.
+// Events already present in the header are not added again, and any detail they have is
+// kept; use TriggerWithPolicy to configure how duplicate events are handled.
 //
-// The returned function will return an error if the provided detail cannot be serialized into JSON.
+// Surrounding whitespace is trimmed from event names. The returned function will return an error
+// wrapping ErrInvalidEventName if an event name is invalid, as determined by ValidateEventName.
 //
 // Parameters:
 //
@@ -227,7 +393,7 @@ func trigger(header string, eventNames ...string) HeaderDecorator {
 //
 // https://htmx.org/headers/hx-trigger/
 func Trigger(eventNames ...string) HeaderDecorator {
-	return trigger(HeaderTrigger, eventNames...)
+	return trigger(HeaderTrigger, DuplicateKeepFirst, eventNames...)
 }
 
 // TriggerAfterSwap sets the HX-Trigger-After-Swap header with the given event names to trigger client side
@@ -238,8 +404,11 @@ func Trigger(eventNames ...string) HeaderDecorator {
 // If the HX-Trigger-After-Swap header already includes events, these will be retained.
 // If the header value is a list of comma separated strings, these will be converted to
 // JSON objects with null detail.
+// Events already present in the header are not added again, and any detail they have is
+// kept; use TriggerWithPolicy to configure how duplicate events are handled.
 //
-// The returned function will return an error if the provided detail cannot be serialized into JSON.
+// Surrounding whitespace is trimmed from event names. The returned function will return an error
+// wrapping ErrInvalidEventName if an event name is invalid, as determined by ValidateEventName.
 //
 // Parameters:
 //
@@ -251,7 +420,7 @@ func Trigger(eventNames ...string) HeaderDecorator {
 //
 // https://htmx.org/headers/hx-trigger/
 func TriggerAfterSwap(eventNames ...string) HeaderDecorator {
-	return trigger(HeaderTriggerAfterSwap, eventNames...)
+	return trigger(HeaderTriggerAfterSwap, DuplicateKeepFirst, eventNames...)
 }
 
 // TriggerAfterSwap sets the HX-Trigger-After-Settle header with the given event names to trigger client side
@@ -262,8 +431,11 @@ func TriggerAfterSwap(eventNames ...string) HeaderDecorator {
 // If the HX-Trigger-After-Settle header already includes events, these will be retained.
 // If the header value is a list of comma separated strings, these will be converted to
 // JSON objects with null detail.
+// Events already present in the header are not added again, and any detail they have is
+// kept; use TriggerWithPolicy to configure how duplicate events are handled.
 //
-// The returned function will return an error if the provided detail cannot be serialized into JSON.
+// Surrounding whitespace is trimmed from event names. The returned function will return an error
+// wrapping ErrInvalidEventName if an event name is invalid, as determined by ValidateEventName.
 //
 // Parameters:
 //
@@ -275,7 +447,7 @@ func TriggerAfterSwap(eventNames ...string) HeaderDecorator {
 //
 // https://htmx.org/headers/hx-trigger/
 func TriggerAfterSettle(eventNames ...string) HeaderDecorator {
-	return trigger(HeaderTriggerAfterSettle, eventNames...)
+	return trigger(HeaderTriggerAfterSettle, DuplicateKeepFirst, eventNames...)
 }
 
 // triggerWithDetail can be used to trigger client side actions on the target element within a response to HTMX.
@@ -291,43 +463,59 @@ func TriggerAfterSettle(eventNames ...string) HeaderDecorator {
 // If the current header value is a list of comma separated strings, these will be converted to
 // JSON objects with null detail.
 //
-// The returned function will return an error if the provided detail cannot be serialized into JSON.
+// If an event has a Target, the target is merged into the detail of the event so that HTMX dispatches
+// the event on the target element.
+//
+// Events are kept in the order they were added, and the JSON object keys are written in that order.
+// An event already present in the header is handled according to the duplicate policy.
+//
+// Surrounding whitespace is trimmed from event names, as it is by trigger. The returned function will
+// return an error wrapping ErrInvalidEventName if an event name is invalid, if the provided detail cannot
+// be serialized into JSON, if the target of an event cannot be merged into its detail, or if an event
+// is a duplicate and the policy is DuplicateError.
 //
 // Parameters:
 //
 //	header: string - Specifies which header should be used to trigger the event.
+//	policy: DuplicatePolicy - Specifies how events already present in the header are handled.
 //	events: ...TriggerEvent - The events (name and detail) to be triggered.
 //
 // Example usage:
 //
 //	event := hx.NewTriggerEvent("eventName", myStruct)
-//	err := hx.SetHeaders(hx.triggerWithDetail(hx.HeaderTrigger, event))
+//	err := hx.SetHeaders(hx.triggerWithDetail(hx.HeaderTrigger, hx.DuplicateKeepLast, event))
 //
 // https://htmx.org/headers/hx-trigger/
-func triggerWithDetail(header string, events ...TriggerEvent) HeaderDecorator {
+func triggerWithDetail(header string, policy DuplicatePolicy, events ...TriggerEvent) HeaderDecorator {
 	return func(w HeaderResponseWriter) error {
-		triggerEvents := make(map[string]any)
-		currentHeaderValue := w.Header().Get(header)
-
 		// If the header already has events present, we want to maintain these.
-		if currentHeaderValue != "" {
-			// Attempt to parse the header value as a JSON object.
-			if err := json.Unmarshal([]byte(currentHeaderValue), &triggerEvents); err != nil {
-				// If not JSON, assume a comma separated list of event names.
-				// Convert these to TriggerEvent structs.
-				eventNames := strings.Split(currentHeaderValue, ",")
-				for _, ev := range eventNames {
-					eventName := strings.TrimSpace(ev)
-					triggerEvents[eventName] = nil
-				}
-			}
+		triggerEvents, err := readTriggerMembers(w.Header().Get(header))
+		if err != nil {
+			return err
 		}
 
 		for _, event := range events {
-			triggerEvents[event.Name] = event.Detail
+			if event.Name, err = normalizeEventName(event.Name); err != nil {
+				return err
+			}
+
+			detail, err := event.detail()
+			if err != nil {
+				return err
+			}
+
+			data, err := json.Marshal(detail)
+			if err != nil {
+				return err
+			}
+
+			triggerEvents, err = mergeTriggerMember(triggerEvents, orderedMember{key: event.Name, value: data}, policy)
+			if err != nil {
+				return err
+			}
 		}
 
-		data, err := json.Marshal(triggerEvents)
+		data, err := encodeOrderedObject(triggerEvents)
 		if err != nil {
 			return err
 		}
@@ -337,6 +525,82 @@ func triggerWithDetail(header string, events ...TriggerEvent) HeaderDecorator {
 	}
 }
 
+// readTriggerMembers reads the events of a trigger header as ordered JSON object members.
+// The header may be a JSON object or a comma separated list of event names; events from
+// the list have null detail, and empty entries in the list are skipped.
+func readTriggerMembers(value string) ([]orderedMember, error) {
+	if strings.TrimSpace(value) == "" {
+		return make([]orderedMember, 0), nil
+	}
+	if isJSONObject(value) {
+		return decodeOrderedObject([]byte(value))
+	}
+
+	members := make([]orderedMember, 0)
+	for _, ev := range strings.Split(value, ",") {
+		if eventName := strings.TrimSpace(ev); eventName != "" {
+			members = append(members, orderedMember{key: eventName, value: json.RawMessage("null")})
+		}
+	}
+	return members, nil
+}
+
+// mergeTriggerMember adds the event to the members according to the duplicate policy.
+func mergeTriggerMember(members []orderedMember, event orderedMember, policy DuplicatePolicy) ([]orderedMember, error) {
+	i := slices.IndexFunc(members, func(m orderedMember) bool { return m.key == event.key })
+	if i < 0 {
+		return append(members, event), nil
+	}
+
+	switch policy {
+	case DuplicateKeepFirst:
+	case DuplicateError:
+		return nil, fmt.Errorf("%w: %q", ErrDuplicateEvent, event.key)
+	default:
+		members[i].value = event.value
+	}
+	return members, nil
+}
+
+// normalizeEventName trims surrounding whitespace from the event name and validates it, so that
+// names are handled the same way by every trigger function.
+func normalizeEventName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if err := ValidateEventName(name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// ValidateEventName returns an error wrapping ErrInvalidEventName if the name cannot be used as a
+// trigger event name. Names must not be empty or consist only of whitespace, and must not contain
+// control characters such as line breaks.
+//
+// Names containing commas or whitespace are valid, but cannot be represented in the comma separated
+// form of the trigger headers; the JSON form is used for these names.
+func ValidateEventName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("%w: name cannot be empty", ErrInvalidEventName)
+	}
+	if strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		return fmt.Errorf("%w: %q contains control characters", ErrInvalidEventName, name)
+	}
+	return nil
+}
+
+// isListEventName reports whether the event name can be represented in the comma separated form
+// of the trigger headers.
+func isListEventName(name string) bool {
+	return !strings.ContainsFunc(name, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+}
+
+// isJSONObject reports whether the header value is in the JSON object form.
+func isJSONObject(value string) bool {
+	return strings.HasPrefix(strings.TrimSpace(value), "{")
+}
+
 // TriggerWithDetail sets the HX-Trigger header with the given TriggerEvent to trigger client side
 // actions on the front end.
 //
@@ -345,8 +609,15 @@ func triggerWithDetail(header string, events ...TriggerEvent) HeaderDecorator {
 // If the HX-Trigger header already includes events, these will be retained.
 // If the header value is a list of comma separated strings, these will be converted to
 // JSON objects with null detail.
+// If an event is already present in the header, its detail is replaced and its position is kept;
+// use TriggerWithDetailAndPolicy to configure how duplicate events are handled.
+//
+// If an event has a Target, it is merged into the event detail so the event is dispatched on the target element.
 //
-// The returned function will return an error if the provided detail cannot be serialized into JSON.
+// Surrounding whitespace is trimmed from event names. The returned function will return an error
+// wrapping ErrInvalidEventName if an event name is invalid, as determined by ValidateEventName, an
+// error if the provided detail cannot be serialized into JSON, or an error if the target of an event
+// cannot be merged into its detail.
 //
 // Parameters:
 //
@@ -359,7 +630,7 @@ func triggerWithDetail(header string, events ...TriggerEvent) HeaderDecorator {
 //
 // https://htmx.org/headers/hx-trigger/
 func TriggerWithDetail(events ...TriggerEvent) HeaderDecorator {
-	return triggerWithDetail(HeaderTrigger, events...)
+	return triggerWithDetail(HeaderTrigger, DuplicateKeepLast, events...)
 }
 
 // TriggerAfterSettleWithDetail sets the HX-Trigger-After-Settle header with the given TriggerEvent
@@ -370,8 +641,15 @@ func TriggerWithDetail(events ...TriggerEvent) HeaderDecorator {
 // If the HX-Trigger-After-Target header already includes events, these will be retained.
 // If the header value is a list of comma separated strings, these will be converted to
 // JSON objects with null detail.
+// If an event is already present in the header, its detail is replaced and its position is kept;
+// use TriggerWithDetailAndPolicy to configure how duplicate events are handled.
+//
+// If an event has a Target, it is merged into the event detail so the event is dispatched on the target element.
 //
-// The returned function will return an error if the provided detail cannot be serialized into JSON.
+// Surrounding whitespace is trimmed from event names. The returned function will return an error
+// wrapping ErrInvalidEventName if an event name is invalid, as determined by ValidateEventName, an
+// error if the provided detail cannot be serialized into JSON, or an error if the target of an event
+// cannot be merged into its detail.
 //
 // Parameters:
 //
@@ -384,7 +662,7 @@ func TriggerWithDetail(events ...TriggerEvent) HeaderDecorator {
 //
 // https://htmx.org/headers/hx-trigger/
 func TriggerAfterSettleWithDetail(events ...TriggerEvent) HeaderDecorator {
-	return triggerWithDetail(HeaderTriggerAfterSettle, events...)
+	return triggerWithDetail(HeaderTriggerAfterSettle, DuplicateKeepLast, events...)
 }
 
 // TriggerAfterSwapWithDetail sets the HX-Trigger-After-Swap header with the given TriggerEvent
@@ -395,8 +673,15 @@ func TriggerAfterSettleWithDetail(events ...TriggerEvent) HeaderDecorator {
 // If the HX-Trigger-After-Swap header already includes events, these will be retained.
 // If the header value is a list of comma separated strings, these will be converted to
 // JSON objects with null detail.
+// If an event is already present in the header, its detail is replaced and its position is kept;
+// use TriggerWithDetailAndPolicy to configure how duplicate events are handled.
 //
-// The returned function will return an error if the provided detail cannot be serialized into JSON.
+// If an event has a Target, it is merged into the event detail so the event is dispatched on the target element.
+//
+// Surrounding whitespace is trimmed from event names. The returned function will return an error
+// wrapping ErrInvalidEventName if an event name is invalid, as determined by ValidateEventName, an
+// error if the provided detail cannot be serialized into JSON, or an error if the target of an event
+// cannot be merged into its detail.
 //
 // Parameters:
 //
@@ -409,5 +694,133 @@ func TriggerAfterSettleWithDetail(events ...TriggerEvent) HeaderDecorator {
 //
 // https://htmx.org/headers/hx-trigger/
 func TriggerAfterSwapWithDetail(events ...TriggerEvent) HeaderDecorator {
-	return triggerWithDetail(HeaderTriggerAfterSwap, events...)
+	return triggerWithDetail(HeaderTriggerAfterSwap, DuplicateKeepLast, events...)
+}
+
+// removeTriggerEvent removes the named events from the given trigger header.
+//
+// The header may be either a comma separated list of event names, as set by the trigger function,
+// or a JSON object, as set by the triggerWithDetail function; the form of the header is retained.
+// If no events remain, the header is removed.
+//
+// The returned function will return an error if the JSON header cannot be parsed.
+func removeTriggerEvent(header string, eventNames ...string) HeaderDecorator {
+	return func(w HeaderResponseWriter) error {
+		currentHeaderValue := w.Header().Get(header)
+		if currentHeaderValue == "" {
+			return nil
+		}
+
+		remove := make(map[string]bool)
+		for _, name := range eventNames {
+			remove[strings.TrimSpace(name)] = true
+		}
+
+		if isJSONObject(currentHeaderValue) {
+			triggerEvents, err := decodeOrderedObject([]byte(currentHeaderValue))
+			if err != nil {
+				return err
+			}
+			triggerEvents = slices.DeleteFunc(triggerEvents, func(m orderedMember) bool { return remove[m.key] })
+			if len(triggerEvents) == 0 {
+				w.Header().Del(header)
+				return nil
+			}
+
+			data, err := encodeOrderedObject(triggerEvents)
+			if err != nil {
+				return err
+			}
+			w.Header().Set(header, string(data))
+			return nil
+		}
+
+		eventList := make([]string, 0)
+		for _, ev := range strings.Split(currentHeaderValue, ",") {
+			eventName := strings.TrimSpace(ev)
+			if eventName != "" && !remove[eventName] {
+				eventList = append(eventList, eventName)
+			}
+		}
+		if len(eventList) == 0 {
+			w.Header().Del(header)
+			return nil
+		}
+
+		w.Header().Set(header, strings.Join(eventList, ", "))
+		return nil
+	}
+}
+
+// RemoveTriggerEvent removes the named events from the HX-Trigger header, allowing events added
+// by earlier middleware or handlers to be overridden.
+//
+// Events are removed from both the comma separated and JSON forms of the header. If no events
+// remain, the header is removed.
+//
+// Example usage:
+//
+//	err := hx.SetHeaders(w, hx.RemoveTriggerEvent("pageview"))
+//
+// https://htmx.org/headers/hx-trigger/
+func RemoveTriggerEvent(eventNames ...string) HeaderDecorator {
+	return removeTriggerEvent(HeaderTrigger, eventNames...)
+}
+
+// RemoveTriggerAfterSwapEvent removes the named events from the HX-Trigger-After-Swap header.
+//
+// Events are removed from both the comma separated and JSON forms of the header. If no events
+// remain, the header is removed.
+//
+// https://htmx.org/headers/hx-trigger/
+func RemoveTriggerAfterSwapEvent(eventNames ...string) HeaderDecorator {
+	return removeTriggerEvent(HeaderTriggerAfterSwap, eventNames...)
+}
+
+// RemoveTriggerAfterSettleEvent removes the named events from the HX-Trigger-After-Settle header.
+//
+// Events are removed from both the comma separated and JSON forms of the header. If no events
+// remain, the header is removed.
+//
+// https://htmx.org/headers/hx-trigger/
+func RemoveTriggerAfterSettleEvent(eventNames ...string) HeaderDecorator {
+	return removeTriggerEvent(HeaderTriggerAfterSettle, eventNames...)
+}
+
+// TriggerWithPolicy adds the given event names to the trigger header of the given phase, handling
+// events already present in the header according to the duplicate policy.
+//
+// As with Trigger, existing events are retained and the form of the header is kept. Events are
+// written in the order they were added.
+//
+// The returned function will return an error wrapping ErrInvalidEventName if an event name is invalid,
+// or wrapping ErrDuplicateEvent if an event is a duplicate and the policy is DuplicateError.
+//
+// Example usage:
+//
+//	err := hx.SetHeaders(w, hx.TriggerWithPolicy(hx.TriggerPhaseAfterSwap, hx.DuplicateError, "event1"))
+//
+// https://htmx.org/headers/hx-trigger/
+func TriggerWithPolicy(phase TriggerPhase, policy DuplicatePolicy, eventNames ...string) HeaderDecorator {
+	return trigger(phase.Header(), policy, eventNames...)
+}
+
+// TriggerWithDetailAndPolicy adds the given events to the trigger header of the given phase, handling
+// events already present in the header according to the duplicate policy.
+//
+// As with TriggerWithDetail, existing events are retained and the header is written as a JSON object
+// with keys in the order the events were added.
+//
+// The returned function will return an error wrapping ErrInvalidEventName if an event name is invalid,
+// an error if the provided detail cannot be serialized into JSON, or an error wrapping ErrDuplicateEvent
+// if an event is a duplicate and the policy is DuplicateError.
+//
+// Example usage:
+//
+//	event := hx.NewTriggerEvent("eventName", myStruct)
+//	err := hx.SetHeaders(w, hx.TriggerWithDetailAndPolicy(hx.TriggerPhaseImmediate, hx.DuplicateKeepFirst, event))
+//
+// https://htmx.org/headers/hx-trigger/
+func TriggerWithDetailAndPolicy(phase TriggerPhase, policy DuplicatePolicy, events ...TriggerEvent) HeaderDecorator {
+	return triggerWithDetail(phase.Header(), policy, events...)
 }
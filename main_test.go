@@ -2,9 +2,11 @@ package hx_test
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/thisisthemurph/hx"
@@ -43,6 +45,33 @@ func TestSetHeader(t *testing.T) {
 
 }
 
+func TestSetHeadersAtomic(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	err := hx.SetHeadersAtomic(w, hx.Retarget("#target"), hx.Trigger("event1"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "#target", w.Header().Get(hx.HeaderRetarget))
+	assert.Equal(t, "event1", w.Header().Get(hx.HeaderTrigger))
+}
+
+func TestSetHeadersAtomic_RestoresHeadersOnError(t *testing.T) {
+	w := httptest.NewRecorder()
+	w.Header().Set(hx.HeaderTrigger, "existing")
+	w.Header().Set("Content-Type", "text/html")
+
+	err := hx.SetHeadersAtomic(w,
+		hx.Retarget("#target"),
+		hx.Trigger("event1"),
+		hx.TriggerWithDetail(hx.NewTriggerEvent("event2", make(chan int))),
+	)
+
+	assert.Error(t, err)
+	assert.Empty(t, w.Header().Get(hx.HeaderRetarget))
+	assert.Equal(t, "existing", w.Header().Get(hx.HeaderTrigger))
+	assert.Equal(t, "text/html", w.Header().Get("Content-Type"))
+}
+
 func TestTrigger(t *testing.T) {
 	w := httptest.NewRecorder()
 
@@ -268,3 +297,526 @@ func TestTriggerWithDetail_ConvertsEventNamesIfAlreadyPresent(t *testing.T) {
 		}
 	}
 }
+
+func TestLocationWithContext(t *testing.T) {
+	swapSpec := hx.NewSwapSpec(hx.SwapOuterHTML).WithSwapDelay(time.Second).WithScroll("", hx.ScrollTop)
+
+	testCases := []struct {
+		name     string
+		ctx      hx.LocationContext
+		expected string
+	}{
+		{
+			name:     "path only",
+			ctx:      hx.LocationContext{Path: "/test"},
+			expected: `{"path":"/test"}`,
+		}, {
+			name: "with target and swap",
+			ctx: hx.LocationContext{
+				Path:   "/test",
+				Target: "#testdiv",
+				Swap:   hx.SwapOuterHTML,
+			},
+			expected: `{"path":"/test","target":"#testdiv","swap":"outerHTML"}`,
+		}, {
+			name: "with swap spec",
+			ctx: hx.LocationContext{
+				Path:     "/test",
+				Swap:     hx.SwapInnerHTML,
+				SwapSpec: &swapSpec,
+			},
+			expected: `{"path":"/test","swap":"outerHTML swap:1s scroll:top"}`,
+		}, {
+			name: "with all values",
+			ctx: hx.LocationContext{
+				Path:    "/test",
+				Source:  "#source",
+				Event:   "click",
+				Handler: "handleResponse",
+				Target:  "#testdiv",
+				Swap:    hx.SwapBeforeEnd,
+				Values:  map[string]any{"id": 1},
+				Headers: map[string]string{"X-Custom": "value"},
+				Select:  "#content",
+			},
+			expected: `{
+				"path": "/test",
+				"source": "#source",
+				"event": "click",
+				"handler": "handleResponse",
+				"target": "#testdiv",
+				"swap": "beforeend",
+				"values": {"id": 1},
+				"headers": {"X-Custom": "value"},
+				"select": "#content"
+			}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			err := hx.SetHeaders(w, hx.LocationWithContext(tc.ctx))
+
+			assert.NoError(t, err)
+			assert.JSONEq(t, tc.expected, w.Header().Get(hx.HeaderLocation))
+		})
+	}
+}
+
+func TestLocationWithContext_ReturnsError(t *testing.T) {
+	testCases := []struct {
+		name string
+		ctx  hx.LocationContext
+	}{
+		{
+			name: "without path",
+			ctx:  hx.LocationContext{Target: "#testdiv"},
+		}, {
+			name: "with invalid swap",
+			ctx:  hx.LocationContext{Path: "/test", Swap: hx.Swap(-1)},
+		}, {
+			name: "with invalid swap spec",
+			ctx: hx.LocationContext{
+				Path:     "/test",
+				SwapSpec: &hx.SwapSpec{Style: hx.SwapOuterHTML, Scroll: &hx.ScrollModifier{Position: "middle"}},
+			},
+		}, {
+			name: "with values that cannot be marshalled",
+			ctx: hx.LocationContext{
+				Path:   "/test",
+				Values: map[string]any{"ch": make(chan int)},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			err := hx.SetHeaders(w, hx.LocationWithContext(tc.ctx))
+
+			assert.Error(t, err)
+			assert.Empty(t, w.Header().Get(hx.HeaderLocation))
+		})
+	}
+}
+
+func TestUnset(t *testing.T) {
+	w := httptest.NewRecorder()
+	w.Header().Set(hx.HeaderRetarget, "#target")
+
+	err := hx.SetHeaders(w, hx.Unset(hx.HeaderRetarget))
+
+	assert.NoError(t, err)
+	assert.Empty(t, w.Header().Values(hx.HeaderRetarget))
+}
+
+func TestClearHTMXHeaders(t *testing.T) {
+	w := httptest.NewRecorder()
+	w.Header().Set("Content-Type", "text/html")
+	_ = hx.SetHeaders(w, hx.Retarget("#target"), hx.Reswap(hx.SwapOuterHTML), hx.Trigger("event1"), hx.PushURL("/path"))
+
+	err := hx.SetHeaders(w, hx.ClearHTMXHeaders())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "text/html", w.Header().Get("Content-Type"))
+	assert.Len(t, w.Header(), 1)
+}
+
+func TestRemoveTriggerEvent(t *testing.T) {
+	testCases := []struct {
+		name           string
+		previousHeader string
+		remove         []string
+		expected       string
+	}{
+		{
+			name:           "comma separated",
+			previousHeader: "pageview, event1, event2",
+			remove:         []string{"pageview"},
+			expected:       "event1, event2",
+		}, {
+			name:           "comma separated multiple",
+			previousHeader: "pageview,event1,event2",
+			remove:         []string{"pageview", "event2"},
+			expected:       "event1",
+		}, {
+			name:           "not present",
+			previousHeader: "event1",
+			remove:         []string{"pageview"},
+			expected:       "event1",
+		}, {
+			name:           "JSON",
+			previousHeader: `{"pageview":null,"event1":{"msg":"hello"}}`,
+			remove:         []string{"pageview"},
+			expected:       `{"event1":{"msg":"hello"}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			w.Header().Set(hx.HeaderTrigger, tc.previousHeader)
+
+			err := hx.SetHeaders(w, hx.RemoveTriggerEvent(tc.remove...))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, w.Header().Get(hx.HeaderTrigger))
+		})
+	}
+}
+
+func TestRemoveTriggerEvent_RemovesHeaderWhenEmpty(t *testing.T) {
+	testCases := []struct {
+		header string
+		remove hx.HeaderDecorator
+	}{
+		{header: hx.HeaderTrigger, remove: hx.RemoveTriggerEvent("event1", "event2")},
+		{header: hx.HeaderTriggerAfterSwap, remove: hx.RemoveTriggerAfterSwapEvent("event1", "event2")},
+		{header: hx.HeaderTriggerAfterSettle, remove: hx.RemoveTriggerAfterSettleEvent("event1", "event2")},
+	}
+
+	for _, tc := range testCases {
+		for _, value := range []string{"event1, event2", `{"event1":null,"event2":"detail"}`} {
+			t.Run(fmt.Sprintf("%s %s", tc.header, value), func(t *testing.T) {
+				w := httptest.NewRecorder()
+				w.Header().Set(tc.header, value)
+
+				err := hx.SetHeaders(w, tc.remove)
+
+				assert.NoError(t, err)
+				assert.Empty(t, w.Header().Values(tc.header))
+			})
+		}
+	}
+}
+
+func TestTriggerWithDetail_MergesTarget(t *testing.T) {
+	testCases := []struct {
+		name     string
+		event    hx.TriggerEvent
+		expected string
+	}{
+		{
+			name:     "nil detail",
+			event:    hx.NewTargetedTriggerEvent("event1", "#other", nil),
+			expected: `{"event1":{"target":"#other"}}`,
+		}, {
+			name: "struct detail",
+			event: hx.NewTargetedTriggerEvent("event1", "#other", struct {
+				Message string `json:"msg"`
+			}{Message: "hello"}),
+			expected: `{"event1":{"msg":"hello","target":"#other"}}`,
+		}, {
+			name:     "map detail",
+			event:    hx.NewTargetedTriggerEvent("event1", "#other", map[string]int{"level": 1}),
+			expected: `{"event1":{"level":1,"target":"#other"}}`,
+		}, {
+			name:     "map detail with matching target",
+			event:    hx.NewTargetedTriggerEvent("event1", "#other", map[string]string{"target": "#other"}),
+			expected: `{"event1":{"target":"#other"}}`,
+		}, {
+			name:     "string detail",
+			event:    hx.NewTargetedTriggerEvent("event1", "#other", "hello"),
+			expected: `{"event1":{"value":"hello","target":"#other"}}`,
+		}, {
+			name:     "number detail",
+			event:    hx.TriggerEvent{Name: "event1", Detail: 42, Target: "#other"},
+			expected: `{"event1":{"value":42,"target":"#other"}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			err := hx.SetHeaders(w, hx.TriggerWithDetail(tc.event))
+
+			assert.NoError(t, err)
+			assert.JSONEq(t, tc.expected, w.Header().Get(hx.HeaderTrigger))
+		})
+	}
+}
+
+func TestTriggerWithDetail_ReturnsErrorWhenTargetCannotBeMerged(t *testing.T) {
+	testCases := []struct {
+		name  string
+		event hx.TriggerEvent
+	}{
+		{
+			name:  "slice detail",
+			event: hx.NewTargetedTriggerEvent("event1", "#other", []string{"a", "b"}),
+		}, {
+			name:  "conflicting target",
+			event: hx.NewTargetedTriggerEvent("event1", "#other", map[string]string{"target": "#different"}),
+		}, {
+			name:  "detail cannot be marshalled",
+			event: hx.NewTargetedTriggerEvent("event1", "#other", make(chan int)),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			err := hx.SetHeaders(w, hx.TriggerWithDetail(tc.event))
+
+			assert.Error(t, err)
+			assert.Empty(t, w.Header().Get(hx.HeaderTrigger))
+		})
+	}
+}
+
+func TestTrigger_DoesNotDuplicateEvents(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	err := hx.SetHeaders(w, hx.Trigger("a"), hx.Trigger("b", "a"), hx.Trigger("a"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "a, b", w.Header().Get(hx.HeaderTrigger))
+}
+
+func TestTrigger_KeepsExistingDetailInJSONHeader(t *testing.T) {
+	testCases := []struct {
+		name    string
+		trigger func(eventNames ...string) hx.HeaderDecorator
+		header  string
+	}{
+		{name: "Trigger", trigger: hx.Trigger, header: hx.HeaderTrigger},
+		{name: "TriggerAfterSwap", trigger: hx.TriggerAfterSwap, header: hx.HeaderTriggerAfterSwap},
+		{name: "TriggerAfterSettle", trigger: hx.TriggerAfterSettle, header: hx.HeaderTriggerAfterSettle},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			w.Header().Set(tc.header, `{"a":{"x":1}}`)
+
+			err := hx.SetHeaders(w, tc.trigger("a", "b"))
+
+			assert.NoError(t, err)
+			assert.Equal(t, `{"a":{"x":1},"b":null}`, w.Header().Get(tc.header))
+		})
+	}
+}
+
+func TestTrigger_SkipsEmptyEntriesInExistingHeader(t *testing.T) {
+	testCases := []struct {
+		name      string
+		decorator hx.HeaderDecorator
+		expected  string
+	}{
+		{
+			name:      "Trigger",
+			decorator: hx.Trigger("c"),
+			expected:  "a, b, c",
+		}, {
+			name:      "TriggerWithDetail",
+			decorator: hx.TriggerWithDetail(hx.NewTriggerEvent("c", 1)),
+			expected:  `{"a":null,"b":null,"c":1}`,
+		}, {
+			name:      "RemoveTriggerEvent",
+			decorator: hx.RemoveTriggerEvent("b"),
+			expected:  "a",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			w.Header().Set(hx.HeaderTrigger, "a,, b, ")
+
+			err := hx.SetHeaders(w, tc.decorator)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, w.Header().Get(hx.HeaderTrigger))
+		})
+	}
+}
+
+func TestTrigger_AfterTriggerWithDetailKeepsDetail(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	err := hx.SetHeaders(w, hx.TriggerWithDetail(hx.NewTriggerEvent("a", map[string]int{"x": 1})), hx.Trigger("a"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":{"x":1}}`, w.Header().Get(hx.HeaderTrigger))
+}
+
+func TestTrigger_NormalizesEventNamesInBothForms(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	err := hx.SetHeaders(w,
+		hx.Trigger("a"),
+		hx.TriggerWithDetail(hx.NewTriggerEvent(" a ", 1)),
+		hx.TriggerWithDetailAndPolicy(hx.TriggerPhaseImmediate, hx.DuplicateKeepFirst, hx.NewTriggerEvent("a\t", 2)),
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, w.Header().Get(hx.HeaderTrigger))
+
+	err = hx.SetHeaders(w, hx.TriggerWithDetailAndPolicy(hx.TriggerPhaseImmediate, hx.DuplicateError, hx.NewTriggerEvent(" a", nil)))
+	assert.ErrorIs(t, err, hx.ErrDuplicateEvent)
+}
+
+func TestTriggerWithDetail_RetainsInsertionOrder(t *testing.T) {
+	w := httptest.NewRecorder()
+	w.Header().Set(hx.HeaderTrigger, "zebra, apple")
+
+	err := hx.SetHeaders(w, hx.TriggerWithDetail(
+		hx.NewTriggerEvent("mango", 1),
+		hx.NewTriggerEvent("banana", map[string]string{"b": "2", "a": "1"}),
+	))
+
+	assert.NoError(t, err)
+	assert.Equal(t, `{"zebra":null,"apple":null,"mango":1,"banana":{"a":"1","b":"2"}}`, w.Header().Get(hx.HeaderTrigger))
+}
+
+func TestTriggerWithDetailAndPolicy(t *testing.T) {
+	testCases := []struct {
+		policy   hx.DuplicatePolicy
+		expected string
+	}{
+		{
+			policy:   hx.DuplicateKeepLast,
+			expected: `{"event1":"second","event2":null}`,
+		}, {
+			policy:   hx.DuplicateKeepFirst,
+			expected: `{"event1":"first","event2":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("policy %d", tc.policy), func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			err := hx.SetHeaders(w,
+				hx.TriggerWithDetailAndPolicy(hx.TriggerPhaseAfterSwap, tc.policy, hx.NewTriggerEvent("event1", "first")),
+				hx.TriggerWithPolicy(hx.TriggerPhaseAfterSwap, tc.policy, "event2"),
+				hx.TriggerWithDetailAndPolicy(hx.TriggerPhaseAfterSwap, tc.policy, hx.NewTriggerEvent("event1", "second")),
+			)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, w.Header().Get(hx.HeaderTriggerAfterSwap))
+		})
+	}
+}
+
+func TestTriggerWithPolicy_ReturnsErrorForDuplicates(t *testing.T) {
+	testCases := []struct {
+		name       string
+		existing   string
+		decorators hx.HeaderDecorator
+	}{
+		{
+			name:       "list form",
+			existing:   "event1, event2",
+			decorators: hx.TriggerWithPolicy(hx.TriggerPhaseImmediate, hx.DuplicateError, "event2"),
+		}, {
+			name:       "JSON form",
+			existing:   `{"event1":null}`,
+			decorators: hx.TriggerWithPolicy(hx.TriggerPhaseImmediate, hx.DuplicateError, "event1"),
+		}, {
+			name:       "with detail",
+			existing:   "event1",
+			decorators: hx.TriggerWithDetailAndPolicy(hx.TriggerPhaseImmediate, hx.DuplicateError, hx.NewTriggerEvent("event1", "detail")),
+		}, {
+			name:       "within new events",
+			decorators: hx.TriggerWithPolicy(hx.TriggerPhaseImmediate, hx.DuplicateError, "event1", "event1"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			if tc.existing != "" {
+				w.Header().Set(hx.HeaderTrigger, tc.existing)
+			}
+
+			err := hx.SetHeaders(w, tc.decorators)
+
+			assert.True(t, errors.Is(err, hx.ErrDuplicateEvent))
+		})
+	}
+}
+
+func TestTriggerPhase_Header(t *testing.T) {
+	assert.Equal(t, hx.HeaderTrigger, hx.TriggerPhaseImmediate.Header())
+	assert.Equal(t, hx.HeaderTriggerAfterSwap, hx.TriggerPhaseAfterSwap.Header())
+	assert.Equal(t, hx.HeaderTriggerAfterSettle, hx.TriggerPhaseAfterSettle.Header())
+}
+
+func TestRemoveTriggerEvent_RetainsOrder(t *testing.T) {
+	w := httptest.NewRecorder()
+	w.Header().Set(hx.HeaderTrigger, `{"zebra":1,"pageview":null,"apple":2}`)
+
+	err := hx.SetHeaders(w, hx.RemoveTriggerEvent("pageview"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, `{"zebra":1,"apple":2}`, w.Header().Get(hx.HeaderTrigger))
+}
+
+func TestTrigger_ReturnsErrorForInvalidEventNames(t *testing.T) {
+	testCases := []struct {
+		name      string
+		decorator hx.HeaderDecorator
+	}{
+		{name: "empty", decorator: hx.Trigger("")},
+		{name: "whitespace", decorator: hx.TriggerAfterSwap("  ")},
+		{name: "empty among valid", decorator: hx.TriggerAfterSettle("event1", "")},
+		{name: "line break", decorator: hx.Trigger("event\r\nX-Injected: true")},
+		{name: "empty with detail", decorator: hx.TriggerWithDetail(hx.NewTriggerEvent("", "detail"))},
+		{name: "control character with detail", decorator: hx.TriggerWithDetail(hx.NewTriggerEvent("event\nX-Injected: true", "detail"))},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			err := hx.SetHeaders(w, tc.decorator)
+
+			assert.True(t, errors.Is(err, hx.ErrInvalidEventName))
+			assert.Empty(t, w.Header())
+		})
+	}
+}
+
+func TestTrigger_UsesJSONForNamesThatCannotBeListed(t *testing.T) {
+	testCases := []struct {
+		name           string
+		previousHeader string
+		events         []string
+		expected       string
+	}{
+		{
+			name:     "comma",
+			events:   []string{"event1", "a,b"},
+			expected: `{"event1":null,"a,b":null}`,
+		}, {
+			name:     "space",
+			events:   []string{"my event"},
+			expected: `{"my event":null}`,
+		}, {
+			name:           "converts existing list",
+			previousHeader: "event1, event2",
+			events:         []string{"my event"},
+			expected:       `{"event1":null,"event2":null,"my event":null}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			if tc.previousHeader != "" {
+				w.Header().Set(hx.HeaderTrigger, tc.previousHeader)
+			}
+
+			err := hx.SetHeaders(w, hx.Trigger(tc.events...))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, w.Header().Get(hx.HeaderTrigger))
+		})
+	}
+}
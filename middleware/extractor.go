@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Extractor reads a value from the request, such as a header set by a HTMX extension or by hx-headers.
+// It reports false if the value is not present.
+type Extractor func(r *http.Request) (string, bool)
+
+// HeaderExtractor returns an Extractor reading the given request header.
+// The value is not extracted if the header is not present.
+func HeaderExtractor(header string) Extractor {
+	return func(r *http.Request) (string, bool) {
+		values := r.Header.Values(header)
+		if len(values) == 0 {
+			return "", false
+		}
+		return values[0], true
+	}
+}
+
+type namedExtractor struct {
+	name string
+	fn   Extractor
+}
+
+var extractors = struct {
+	sync.RWMutex
+	list []namedExtractor
+}{}
+
+// RegisterExtractor registers an Extractor, whose value is made available in HTMXRequest.Extensions
+// under the given name by the WithHTMX middleware. This allows headers provided by HTMX extensions,
+// or custom headers sent with hx-headers, to be read alongside the standard HTMX request headers.
+//
+// An error is returned if the name is empty or contains whitespace, if the extractor is nil, or if an
+// extractor with the same name has already been registered. Extractors are typically registered when
+// initializing the program.
+//
+// Example usage:
+//
+//	func init() {
+//		_ = middleware.RegisterExtractor("requestType", middleware.HeaderExtractor("HX-Request-Type"))
+//	}
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//		h, _ := middleware.GetRequestHeaders(r)
+//		requestType, ok := h.Extension("requestType")
+//	}
+func RegisterExtractor(name string, fn Extractor) error {
+	if err := validateExtractor(name, fn); err != nil {
+		return err
+	}
+
+	extractors.Lock()
+	defer extractors.Unlock()
+	for _, e := range extractors.list {
+		if e.name == name {
+			return fmt.Errorf("extractor %q is already registered", name)
+		}
+	}
+	extractors.list = append(extractors.list, namedExtractor{name: name, fn: fn})
+	return nil
+}
+
+// validateExtractor returns an error if the name is empty or contains whitespace, or the extractor is nil.
+func validateExtractor(name string, fn Extractor) error {
+	if strings.TrimSpace(name) == "" || strings.ContainsAny(name, " \t\r\n") {
+		return fmt.Errorf("invalid extractor name %q", name)
+	}
+	if fn == nil {
+		return errors.New("extractor must not be nil")
+	}
+	return nil
+}
+
+// extract runs the registered extractors, followed by the additional extractors, against the request.
+// If extractors share a name, the value of the last is used. Nil is returned if no values were extracted.
+func extract(r *http.Request, additional []namedExtractor) map[string]string {
+	extractors.RLock()
+	list := append(extractors.list[:len(extractors.list):len(extractors.list)], additional...)
+	extractors.RUnlock()
+
+	var values map[string]string
+	for _, e := range list {
+		value, ok := e.fn(r)
+		if !ok {
+			continue
+		}
+		if values == nil {
+			values = make(map[string]string)
+		}
+		values[e.name] = value
+	}
+	return values
+}
@@ -0,0 +1,79 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thisisthemurph/hx/middleware"
+)
+
+// registerTestExtractors registers the extractors once, as the registry is global and tests may be run repeatedly.
+var registerTestExtractors = sync.OnceValue(func() error {
+	if err := middleware.RegisterExtractor("test.requestType", middleware.HeaderExtractor("HX-Request-Type")); err != nil {
+		return err
+	}
+	return middleware.RegisterExtractor("test.tenant", func(r *http.Request) (string, bool) {
+		tenant := r.Header.Get("X-Tenant")
+		return tenant, tenant != ""
+	})
+})
+
+func TestRegisterExtractor(t *testing.T) {
+	assert.NoError(t, registerTestExtractors())
+
+	testCases := []struct {
+		name               string
+		requestHeaders     map[string]string
+		expectedExtensions map[string]string
+	}{
+		{
+			name:           "all values present",
+			requestHeaders: map[string]string{"HX-Request-Type": "partial", "X-Tenant": "acme"},
+			expectedExtensions: map[string]string{
+				"test.requestType": "partial",
+				"test.tenant":      "acme",
+			},
+		}, {
+			name:               "empty header value is extracted",
+			requestHeaders:     map[string]string{"HX-Request-Type": ""},
+			expectedExtensions: map[string]string{"test.requestType": ""},
+		}, {
+			name: "no values present",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for key, value := range tc.requestHeaders {
+				req.Header.Set(key, value)
+			}
+
+			handler := middleware.WithHTMX(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				h, ok := middleware.GetRequestHeaders(r)
+				assert.True(t, ok)
+				assert.Equal(t, tc.expectedExtensions, h.Extensions)
+
+				for name, expected := range tc.expectedExtensions {
+					value, ok := h.Extension(name)
+					assert.True(t, ok)
+					assert.Equal(t, expected, value)
+				}
+			}))
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+		})
+	}
+}
+
+func TestRegisterExtractor_Errors(t *testing.T) {
+	extractor := middleware.HeaderExtractor("X-Test")
+
+	_ = middleware.RegisterExtractor("test.duplicate", extractor)
+	assert.Error(t, middleware.RegisterExtractor("test.duplicate", extractor))
+	assert.Error(t, middleware.RegisterExtractor("", extractor))
+	assert.Error(t, middleware.RegisterExtractor("test name", extractor))
+	assert.Error(t, middleware.RegisterExtractor("test.nil", nil))
+}
@@ -10,6 +10,7 @@ const (
 	headerRequest               string = "HX-Request"
 	headerCurrentURL            string = "HX-Current-URL"
 	headerHistoryRestoreRequest string = "HX-History-Restore-Request"
+	headerPrompt                string = "HX-Prompt"
 	headerTarget                string = "HX-Target"
 	headerTrigger               string = "HX-Trigger"
 	headerTriggerName           string = "HX-Trigger-Name"
@@ -22,33 +23,56 @@ const HTMXRequestKey ContextKey = "HTMXRequest"
 // HTMXRequest is a struct detailing HTMX request header values.
 // HTMX documentation: https://htmx.org/reference/#request_headers
 type HTMXRequest struct {
-	CurrentURL              string // The current URL of the browser.
+	CurrentURL              string // The current URL of the browser; see ParseCurrentURL and IsSameOrigin.
 	IsBoosted               bool   // Indicates that the request is via an element using hx-boost.
 	IsHistoryRestoreRequest bool   // Indicates if the request is for history restoration after a miss in the local history cache.
 	IsHTMXRequest           bool   // Indicates if the request was a HTMX request; false if the HX-Request header is not present.
+	Prompt                  string // The user response to an hx-prompt, if it exists.
 	Target                  string // The id of the triggering element, if it exists.
 	Trigger                 string // The id of the triggered element, if it exists.
 	TriggerName             string // The name of the triggering element, if it exists.
+
+	// Extensions holds the values read by the extractors registered with RegisterExtractor, keyed by
+	// the extractor name. It is nil if no values were extracted.
+	Extensions map[string]string
+}
+
+// Extension returns the value read by the extractor registered with the given name, and whether a value was extracted.
+func (h HTMXRequest) Extension(name string) (string, bool) {
+	value, ok := h.Extensions[name]
+	return value, ok
+}
+
+// IsPartial reports whether the response to the request is processed by HTMX, such as a fragment to
+// be swapped into the page. It is false for full page requests and history restore requests, which
+// expect a full page.
+func (h HTMXRequest) IsPartial() bool {
+	return h.IsHTMXRequest && !h.IsHistoryRestoreRequest
 }
 
 // WithHTMX is a middleware function for interpreting the HTMX request headers and making
 // them available within the handler's context. If the request is not a HTMX request, the
 // HTMXRequest result will take all default values.
+//
+// WithHTMX is the default configuration of the middleware returned by New.
 func WithHTMX(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		htmxRequest := HTMXRequest{
-			CurrentURL:              r.Header.Get(headerCurrentURL),
-			IsBoosted:               r.Header.Get(headerBoosted) == "true",
-			IsHistoryRestoreRequest: r.Header.Get(headerHistoryRestoreRequest) == "true",
-			IsHTMXRequest:           r.Header.Get(headerRequest) == "true",
-			Target:                  r.Header.Get(headerTarget),
-			Trigger:                 r.Header.Get(headerTrigger),
-			TriggerName:             r.Header.Get(headerTriggerName),
-		}
+	return New()(next)
+}
 
-		ctx := context.WithValue(r.Context(), HTMXRequestKey, htmxRequest)
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+// parseRequest reads the HTMX request headers, and the values of any registered extractors and
+// the given additional extractors, from the request.
+func parseRequest(r *http.Request, additional ...namedExtractor) HTMXRequest {
+	return HTMXRequest{
+		CurrentURL:              r.Header.Get(headerCurrentURL),
+		IsBoosted:               r.Header.Get(headerBoosted) == "true",
+		IsHistoryRestoreRequest: r.Header.Get(headerHistoryRestoreRequest) == "true",
+		IsHTMXRequest:           r.Header.Get(headerRequest) == "true",
+		Prompt:                  r.Header.Get(headerPrompt),
+		Target:                  r.Header.Get(headerTarget),
+		Trigger:                 r.Header.Get(headerTrigger),
+		TriggerName:             r.Header.Get(headerTriggerName),
+		Extensions:              extract(r, additional),
+	}
 }
 
 // GetRequestHeaders extracts the HTMXRequest headers from the provided HTTP request.
@@ -66,6 +90,48 @@ func WithHTMX(next http.Handler) http.Handler {
 //
 //	htmxRequest, ok := r.Context().Value(middleware.HTMXRequestKey).(middleware.HTMXRequest)
 func GetRequestHeaders(r *http.Request) (HTMXRequest, bool) {
-	htmxRequest, ok := r.Context().Value(HTMXRequestKey).(HTMXRequest)
+	return FromContext(r.Context())
+}
+
+// FromRequest returns the HTMXRequest headers for the provided HTTP request.
+// If the WithHTMX middleware, or a middleware returned by New with the default context key, has
+// stored the HTMXRequest in the request's context, that value is returned. Otherwise the headers,
+// and the values of any registered extractors, are parsed from the request directly.
+//
+// Unlike GetRequestHeaders, FromRequest does not require the middleware to be configured.
+//
+// Example usage:
+//
+//	if middleware.FromRequest(r).IsHTMXRequest {
+//		renderFragment(w)
+//		return
+//	}
+func FromRequest(r *http.Request) HTMXRequest {
+	if htmxRequest, ok := GetRequestHeaders(r); ok {
+		return htmxRequest
+	}
+	return parseRequest(r)
+}
+
+// WithHTMXRequest returns a copy of ctx in which the HTMXRequest is stored under HTMXRequestKey,
+// to be read with FromContext. This is useful when passing the request context to services, or
+// in tests of code which only has access to a context.Context.
+func WithHTMXRequest(ctx context.Context, h HTMXRequest) context.Context {
+	return context.WithValue(ctx, HTMXRequestKey, h)
+}
+
+// FromContext returns the HTMXRequest stored in the context by the WithHTMX middleware or
+// WithHTMXRequest, and whether it was found. This allows services which only have access to a
+// context.Context to check whether they are serving a HTMX request.
+//
+// Example usage:
+//
+//	func (s *ItemService) List(ctx context.Context) ([]Item, error) {
+//		if h, ok := middleware.FromContext(ctx); ok && h.IsHTMXRequest {
+//			// Return only the first page for the fragment.
+//		}
+//	}
+func FromContext(ctx context.Context) (HTMXRequest, bool) {
+	htmxRequest, ok := ctx.Value(HTMXRequestKey).(HTMXRequest)
 	return htmxRequest, ok
 }
@@ -1,6 +1,7 @@
 package middleware_test
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -23,6 +24,7 @@ func TestWithHTMXMiddleware_WhenHTMXRequest(t *testing.T) {
 	req.Header.Set("HX-Boosted", "true")
 	req.Header.Set("HX-History-Restore-Request", "true")
 	req.Header.Set("HX-Request", "true")
+	req.Header.Set("HX-Prompt", "prompt response")
 	req.Header.Set("HX-Target", "confirm-btn")
 	req.Header.Set("HX-Trigger", "notification-section")
 	req.Header.Set("HX-Trigger-Name", "trigger-name")
@@ -38,6 +40,7 @@ func TestWithHTMXMiddleware_WhenHTMXRequest(t *testing.T) {
 		assert.True(t, h.IsBoosted)
 		assert.True(t, h.IsHistoryRestoreRequest)
 		assert.True(t, h.IsHTMXRequest)
+		assert.Equal(t, "prompt response", h.Prompt)
 		assert.Equal(t, "confirm-btn", h.Target)
 		assert.Equal(t, "notification-section", h.Trigger)
 		assert.Equal(t, "trigger-name", h.TriggerName)
@@ -71,6 +74,7 @@ func TestWithHTMXMiddleware_WhenNotHTMXRequest(t *testing.T) {
 		assert.False(t, h.IsBoosted)
 		assert.False(t, h.IsHistoryRestoreRequest)
 		assert.False(t, h.IsHTMXRequest)
+		assert.Empty(t, h.Prompt)
 		assert.Empty(t, h.Target)
 		assert.Empty(t, h.Trigger)
 		assert.Empty(t, h.TriggerName)
@@ -108,3 +112,72 @@ func TestWithHTMX_OkIsFalse_WhenMiddlewareNotConfigured(t *testing.T) {
 			status, http.StatusOK)
 	}
 }
+
+func TestFromRequest(t *testing.T) {
+	testCases := []struct {
+		name           string
+		withMiddleware bool
+	}{
+		{name: "with middleware", withMiddleware: true},
+		{name: "without middleware"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("HX-Request", "true")
+			req.Header.Set("HX-Target", "content")
+
+			var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				h := middleware.FromRequest(r)
+				assert.True(t, h.IsHTMXRequest)
+				assert.Equal(t, "content", h.Target)
+			})
+			if tc.withMiddleware {
+				handler = middleware.WithHTMX(handler)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+		})
+	}
+}
+
+func TestFromRequest_PrefersContextValue(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("HX-Request", "true")
+	req = req.WithContext(middleware.WithHTMXRequest(req.Context(), middleware.HTMXRequest{Target: "from-context"}))
+
+	h := middleware.FromRequest(req)
+
+	assert.False(t, h.IsHTMXRequest)
+	assert.Equal(t, "from-context", h.Target)
+}
+
+func TestFromContext(t *testing.T) {
+	h, ok := middleware.FromContext(context.Background())
+	assert.False(t, ok)
+	assert.Equal(t, middleware.HTMXRequest{}, h)
+
+	expected := middleware.HTMXRequest{IsHTMXRequest: true, Target: "content"}
+	h, ok = middleware.FromContext(middleware.WithHTMXRequest(context.Background(), expected))
+	assert.True(t, ok)
+	assert.Equal(t, expected, h)
+}
+
+func TestHTMXRequest_IsPartial(t *testing.T) {
+	testCases := []struct {
+		name     string
+		request  middleware.HTMXRequest
+		expected bool
+	}{
+		{name: "htmx request", request: middleware.HTMXRequest{IsHTMXRequest: true}, expected: true},
+		{name: "boosted request", request: middleware.HTMXRequest{IsHTMXRequest: true, IsBoosted: true}, expected: true},
+		{name: "history restore request", request: middleware.HTMXRequest{IsHTMXRequest: true, IsHistoryRestoreRequest: true}, expected: false},
+		{name: "full page request", request: middleware.HTMXRequest{}, expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.request.IsPartial())
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/thisisthemurph/hx/internal/hook"
+)
+
+// Option configures the middleware returned by New.
+type Option func(*config)
+
+type config struct {
+	contextKey     any
+	vary           bool
+	historyNoStore bool
+	requireHTMX    []func(r *http.Request) bool
+	trustedOrigins []string
+	hooks          []func(r *http.Request, h HTMXRequest)
+	extractors     []namedExtractor
+}
+
+// New returns a middleware function for interpreting the HTMX request headers and making them
+// available within the handler's context, configured with the given options. With no options,
+// it behaves the same as WithHTMX.
+//
+// The request is processed in the following order: the headers are parsed, HX-Current-URL is checked
+// against any trusted origins, the hooks are called, the Vary header is set, and finally requests
+// required to be HTMX requests are rejected if they are not.
+//
+// WithVary and WithHistoryRestoreNoStore wrap the response writer. The wrapper implements
+// http.Flusher and http.Hijacker only if the original writer does, so WebSocket upgrades are unaffected.
+//
+// Example usage:
+//
+//	htmx := middleware.New(
+//		middleware.WithVary(),
+//		middleware.WithTrustedOrigins("https://example.com"),
+//		middleware.RequireHTMX(func(r *http.Request) bool {
+//			return strings.HasPrefix(r.URL.Path, "/partials/")
+//		}),
+//	)
+//	http.ListenAndServe(":8080", htmx(mux))
+func New(opts ...Option) func(http.Handler) http.Handler {
+	cfg := config{contextKey: HTMXRequestKey}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			htmxRequest := parseRequest(r, cfg.extractors...)
+			if len(cfg.trustedOrigins) > 0 && !cfg.isTrustedOrigin(htmxRequest.CurrentURL) {
+				htmxRequest.CurrentURL = ""
+			}
+
+			for _, hook := range cfg.hooks {
+				hook(r, htmxRequest)
+			}
+
+			if cfg.vary || (cfg.historyNoStore && htmxRequest.IsHistoryRestoreRequest) {
+				c := &cacheHeaders{header: w.Header(), vary: cfg.vary, noStore: cfg.historyNoStore && htmxRequest.IsHistoryRestoreRequest}
+				defer c.apply()
+				w = hook.Wrap(&hook.Writer{ResponseWriter: w, Before: c.apply})
+			}
+
+			if !htmxRequest.IsHTMXRequest && cfg.requiresHTMX(r) {
+				http.Error(w, "HTMX request required", http.StatusBadRequest)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), cfg.contextKey, htmxRequest)
+			if cfg.contextKey != HTMXRequestKey {
+				// Store the value under the default key too, so that FromRequest and the helpers using
+				// it see the checked HX-Current-URL rather than parsing the headers again.
+				ctx = context.WithValue(ctx, HTMXRequestKey, htmxRequest)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// WithContextKey sets the key under which the HTMXRequest is stored in the request context, which
+// can be read from the context directly:
+//
+//	h, ok := r.Context().Value(key).(middleware.HTMXRequest)
+//
+// The HTMXRequest is also stored under HTMXRequestKey, so GetRequestHeaders, FromRequest and
+// FromContext return the same value, including any changes made by WithTrustedOrigins.
+func WithContextKey(key any) Option {
+	return func(c *config) {
+		c.contextKey = key
+	}
+}
+
+// varyHeaders are the request headers which change the response to a HTMX request.
+var varyHeaders = []string{headerRequest, headerBoosted, headerHistoryRestoreRequest}
+
+// WithVary adds HX-Request, HX-Boosted and HX-History-Restore-Request to the Vary response header,
+// so that caches store the full page and partial responses for the same URL separately.
+//
+// The headers are merged with any existing Vary values just before the response headers are written,
+// so values set by the handler, including with Header().Set, are retained. Headers already present are
+// not repeated, and nothing is added if the Vary header is "*".
+func WithVary() Option {
+	return func(c *config) {
+		c.vary = true
+	}
+}
+
+// WithHistoryRestoreNoStore sets Cache-Control: no-store on responses to history restore requests,
+// replacing any value set by the handler. These responses contain the full page, and must not be
+// cached in place of the partial responses usually returned to HTMX requests.
+func WithHistoryRestoreNoStore() Option {
+	return func(c *config) {
+		c.historyNoStore = true
+	}
+}
+
+// RequireHTMX responds with 400 Bad Request to requests which are not HTMX requests, for the requests
+// matching the given function. If the function is nil, all requests must be HTMX requests.
+// RequireHTMX may be given multiple times; a request is rejected if it matches any of the functions.
+func RequireHTMX(match func(r *http.Request) bool) Option {
+	if match == nil {
+		match = func(*http.Request) bool { return true }
+	}
+	return func(c *config) {
+		c.requireHTMX = append(c.requireHTMX, match)
+	}
+}
+
+// WithTrustedOrigins restricts HX-Current-URL to the given origins, such as "https://example.com".
+// If the origin of HX-Current-URL is not one of the trusted origins, or it cannot be parsed,
+// HTMXRequest.CurrentURL is left empty. The comparison ignores case, any trailing slash, and the
+// default port of the scheme, as IsSameOrigin does.
+//
+// The check only applies to the HTMXRequest stored by the middleware. FromRequest returns the stored
+// value, but parses HX-Current-URL unchecked for requests which have not passed through the middleware.
+func WithTrustedOrigins(origins ...string) Option {
+	return func(c *config) {
+		for _, trusted := range origins {
+			trusted = strings.TrimSuffix(trusted, "/")
+			if u, err := url.Parse(trusted); err == nil && u.Scheme != "" && u.Host != "" {
+				trusted = origin(u.Scheme, u.Host)
+			}
+			c.trustedOrigins = append(c.trustedOrigins, strings.ToLower(trusted))
+		}
+	}
+}
+
+// WithHook adds a function called with the request and the parsed HTMXRequest before the next
+// handler is called, such as for logging or metrics. Hooks are called in the order they are given.
+func WithHook(fn func(r *http.Request, h HTMXRequest)) Option {
+	return func(c *config) {
+		c.hooks = append(c.hooks, fn)
+	}
+}
+
+// WithExtractor adds an Extractor to the middleware, in addition to those registered with
+// RegisterExtractor. If the name matches a registered extractor, this extractor takes precedence.
+//
+// WithExtractor panics if the name is empty or contains whitespace, or the extractor is nil.
+func WithExtractor(name string, fn Extractor) Option {
+	if err := validateExtractor(name, fn); err != nil {
+		panic(err)
+	}
+	return func(c *config) {
+		c.extractors = append(c.extractors, namedExtractor{name: name, fn: fn})
+	}
+}
+
+// requiresHTMX reports whether the request must be a HTMX request.
+func (c *config) requiresHTMX(r *http.Request) bool {
+	for _, match := range c.requireHTMX {
+		if match(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTrustedOrigin reports whether the origin of the given URL is one of the trusted origins.
+func (c *config) isTrustedOrigin(currentURL string) bool {
+	u, err := url.Parse(currentURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return false
+	}
+
+	current := origin(u.Scheme, u.Host)
+	for _, trusted := range c.trustedOrigins {
+		if current == trusted {
+			return true
+		}
+	}
+	return false
+}
+
+// addVary adds the given headers to the Vary header, omitting any already present.
+// Nothing is added if the Vary header is "*".
+func addVary(h http.Header, headers ...string) {
+	present := make(map[string]bool)
+	for _, value := range h.Values("Vary") {
+		for _, field := range strings.Split(value, ",") {
+			field = strings.TrimSpace(field)
+			if field == "*" {
+				return
+			}
+			present[http.CanonicalHeaderKey(field)] = true
+		}
+	}
+
+	missing := make([]string, 0, len(headers))
+	for _, header := range headers {
+		if !present[http.CanonicalHeaderKey(header)] {
+			missing = append(missing, header)
+		}
+	}
+	if len(missing) > 0 {
+		h.Add("Vary", strings.Join(missing, ", "))
+	}
+}
+
+// cacheHeaders sets the Vary and Cache-Control headers just before the response headers are written.
+type cacheHeaders struct {
+	header  http.Header
+	vary    bool
+	noStore bool
+	applied bool
+}
+
+func (c *cacheHeaders) apply() {
+	if c.applied {
+		return
+	}
+	c.applied = true
+	if c.vary {
+		addVary(c.header, varyHeaders...)
+	}
+	if c.noStore {
+		c.header.Set("Cache-Control", "no-store")
+	}
+}
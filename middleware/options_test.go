@@ -0,0 +1,370 @@
+package middleware_test
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thisisthemurph/hx/middleware"
+)
+
+func TestNew_DefaultMatchesWithHTMX(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("HX-Request", "true")
+	req.Header.Set("HX-Target", "content")
+
+	var fromNew, fromWithHTMX middleware.HTMXRequest
+	middleware.New()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fromNew, _ = middleware.GetRequestHeaders(r)
+	})).ServeHTTP(httptest.NewRecorder(), req)
+	middleware.WithHTMX(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fromWithHTMX, _ = middleware.GetRequestHeaders(r)
+	})).ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.True(t, fromNew.IsHTMXRequest)
+	assert.Equal(t, fromWithHTMX, fromNew)
+}
+
+func TestNew_WithContextKey(t *testing.T) {
+	const key middleware.ContextKey = "custom"
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("HX-Request", "true")
+
+	handler := middleware.New(middleware.WithContextKey(key))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h, ok := r.Context().Value(key).(middleware.HTMXRequest)
+		assert.True(t, ok)
+		assert.True(t, h.IsHTMXRequest)
+
+		fromRequest, ok := middleware.GetRequestHeaders(r)
+		assert.True(t, ok)
+		assert.Equal(t, h, fromRequest)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestNew_WithContextKey_FromRequestUsesTrustedOrigins(t *testing.T) {
+	const key middleware.ContextKey = "custom"
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("HX-Request", "true")
+	req.Header.Set("HX-Current-URL", "https://evil.com/")
+
+	handler := middleware.New(
+		middleware.WithContextKey(key),
+		middleware.WithTrustedOrigins("https://good.com"),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := middleware.FromRequest(r)
+		assert.True(t, h.IsHTMXRequest)
+		assert.Empty(t, h.CurrentURL)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestNew_WithVary(t *testing.T) {
+	const all = "HX-Request, HX-Boosted, HX-History-Restore-Request"
+
+	testCases := []struct {
+		name         string
+		existingVary []string
+		handler      http.HandlerFunc
+		expectedVary []string
+	}{
+		{
+			name:         "no existing vary",
+			handler:      func(w http.ResponseWriter, r *http.Request) {},
+			expectedVary: []string{all},
+		}, {
+			name:         "existing vary",
+			existingVary: []string{"Accept-Encoding"},
+			handler:      func(w http.ResponseWriter, r *http.Request) {},
+			expectedVary: []string{"Accept-Encoding", all},
+		}, {
+			name:         "partially present",
+			existingVary: []string{"Accept-Encoding, hx-request"},
+			handler:      func(w http.ResponseWriter, r *http.Request) {},
+			expectedVary: []string{"Accept-Encoding, hx-request", "HX-Boosted, HX-History-Restore-Request"},
+		}, {
+			name:         "all present",
+			existingVary: []string{all},
+			handler:      func(w http.ResponseWriter, r *http.Request) {},
+			expectedVary: []string{all},
+		}, {
+			name:         "vary all",
+			existingVary: []string{"*"},
+			handler:      func(w http.ResponseWriter, r *http.Request) {},
+			expectedVary: []string{"*"},
+		}, {
+			name: "handler replaces vary before write header",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Vary", "Accept-Language")
+				w.WriteHeader(http.StatusOK)
+			},
+			expectedVary: []string{"Accept-Language", all},
+		}, {
+			name: "handler writes body",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Vary", "Cookie")
+				_, _ = w.Write([]byte("hello"))
+			},
+			expectedVary: []string{"Cookie", all},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			for _, value := range tc.existingVary {
+				rr.Header().Add("Vary", value)
+			}
+
+			handler := middleware.New(middleware.WithVary())(tc.handler)
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			assert.Equal(t, tc.expectedVary, rr.Result().Header.Values("Vary"))
+		})
+	}
+}
+
+func TestNew_WithVary_RequireHTMXResponse(t *testing.T) {
+	rr := httptest.NewRecorder()
+
+	handler := middleware.New(middleware.WithVary(), middleware.RequireHTMX(nil))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, []string{"HX-Request, HX-Boosted, HX-History-Restore-Request"}, rr.Result().Header.Values("Vary"))
+}
+
+func TestNew_WithHistoryRestoreNoStore(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		requestHeaders       map[string]string
+		expectedCacheControl string
+	}{
+		{
+			name:                 "history restore request",
+			requestHeaders:       map[string]string{"HX-Request": "true", "HX-History-Restore-Request": "true"},
+			expectedCacheControl: "no-store",
+		}, {
+			name:                 "htmx request",
+			requestHeaders:       map[string]string{"HX-Request": "true"},
+			expectedCacheControl: "max-age=60",
+		}, {
+			name:                 "full page request",
+			expectedCacheControl: "max-age=60",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for key, value := range tc.requestHeaders {
+				req.Header.Set(key, value)
+			}
+			rr := httptest.NewRecorder()
+
+			handler := middleware.New(middleware.WithHistoryRestoreNoStore())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Cache-Control", "max-age=60")
+				_, _ = w.Write([]byte("page"))
+			}))
+			handler.ServeHTTP(rr, req)
+
+			res := rr.Result()
+			assert.Equal(t, tc.expectedCacheControl, res.Header.Get("Cache-Control"))
+			assert.Empty(t, res.Header.Values("Vary"))
+		})
+	}
+}
+
+func TestNew_RequireHTMX(t *testing.T) {
+	partials := func(r *http.Request) bool {
+		return strings.HasPrefix(r.URL.Path, "/partials/")
+	}
+
+	testCases := []struct {
+		name           string
+		match          func(r *http.Request) bool
+		path           string
+		isHTMXRequest  bool
+		expectedStatus int
+	}{
+		{
+			name:           "matching htmx request",
+			match:          partials,
+			path:           "/partials/list",
+			isHTMXRequest:  true,
+			expectedStatus: http.StatusOK,
+		}, {
+			name:           "matching non-htmx request",
+			match:          partials,
+			path:           "/partials/list",
+			expectedStatus: http.StatusBadRequest,
+		}, {
+			name:           "non-matching non-htmx request",
+			match:          partials,
+			path:           "/",
+			expectedStatus: http.StatusOK,
+		}, {
+			name:           "nil matches all requests",
+			path:           "/",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.isHTMXRequest {
+				req.Header.Set("HX-Request", "true")
+			}
+			rr := httptest.NewRecorder()
+
+			handler := middleware.New(middleware.RequireHTMX(tc.match))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestNew_WithTrustedOrigins(t *testing.T) {
+	testCases := []struct {
+		name               string
+		currentURL         string
+		expectedCurrentURL string
+	}{
+		{
+			name:               "trusted origin",
+			currentURL:         "https://example.com/items?page=2",
+			expectedCurrentURL: "https://example.com/items?page=2",
+		}, {
+			name:               "trusted origin with different case",
+			currentURL:         "https://EXAMPLE.com/items",
+			expectedCurrentURL: "https://EXAMPLE.com/items",
+		}, {
+			name:       "untrusted origin",
+			currentURL: "https://evil.example/items",
+		}, {
+			name:       "different scheme",
+			currentURL: "http://example.com/items",
+		}, {
+			name:               "trusted origin with default port",
+			currentURL:         "https://example.com:443/a",
+			expectedCurrentURL: "https://example.com:443/a",
+		}, {
+			name:       "different port",
+			currentURL: "https://example.com:8443/items",
+		}, {
+			name:       "relative url",
+			currentURL: "/items",
+		}, {
+			name:       "malformed url",
+			currentURL: "https://example.com/%zz",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("HX-Request", "true")
+			req.Header.Set("HX-Current-URL", tc.currentURL)
+
+			handler := middleware.New(middleware.WithTrustedOrigins("https://example.com/"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				h, _ := middleware.GetRequestHeaders(r)
+				assert.Equal(t, tc.expectedCurrentURL, h.CurrentURL)
+			}))
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+		})
+	}
+}
+
+func TestNew_WithHook(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("HX-Request", "true")
+	req.Header.Set("HX-Current-URL", "https://evil.example/")
+
+	calls := make([]string, 0)
+	handler := middleware.New(
+		middleware.WithTrustedOrigins("https://example.com"),
+		middleware.WithHook(func(r *http.Request, h middleware.HTMXRequest) {
+			assert.True(t, h.IsHTMXRequest)
+			assert.Empty(t, h.CurrentURL)
+			calls = append(calls, "first")
+		}),
+		middleware.WithHook(func(r *http.Request, h middleware.HTMXRequest) {
+			calls = append(calls, "second")
+		}),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "handler")
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, []string{"first", "second", "handler"}, calls)
+}
+
+func TestNew_WithExtractor(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Tenant", "acme")
+
+	handler := middleware.New(middleware.WithExtractor("tenant", middleware.HeaderExtractor("X-Tenant")))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h, _ := middleware.GetRequestHeaders(r)
+		tenant, ok := h.Extension("tenant")
+		assert.True(t, ok)
+		assert.Equal(t, "acme", tenant)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Panics(t, func() { middleware.WithExtractor("", middleware.HeaderExtractor("X-Tenant")) })
+}
+
+func TestNew_WithTrustedOrigins_DefaultPort(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("HX-Request", "true")
+	req.Header.Set("HX-Current-URL", "http://example.com/items")
+
+	handler := middleware.New(middleware.WithTrustedOrigins("http://example.com:80"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h, _ := middleware.GetRequestHeaders(r)
+		assert.Equal(t, "http://example.com/items", h.CurrentURL)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+// hijackRecorder is a httptest.ResponseRecorder implementing http.Hijacker.
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (r *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	r.hijacked = true
+	return nil, nil, nil
+}
+
+func TestNew_WrappedWriterKeepsHijacker(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("HX-History-Restore-Request", "true")
+	rec := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+
+	handler := middleware.New(middleware.WithVary(), middleware.WithHistoryRestoreNoStore())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hijacker, ok := w.(http.Hijacker)
+		if assert.True(t, ok) {
+			_, _, _ = hijacker.Hijack()
+		}
+	}))
+	handler.ServeHTTP(rec, req)
+
+	assert.True(t, rec.hijacked)
+}
+
+func TestNew_WrappedWriterOnlyFlushesWhenSupported(t *testing.T) {
+	handler := middleware.New(middleware.WithVary())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, isFlusher := w.(http.Flusher)
+		assert.False(t, isFlusher)
+	}))
+	handler.ServeHTTP(struct{ http.ResponseWriter }{httptest.NewRecorder()}, httptest.NewRequest(http.MethodGet, "/", nil))
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var (
+	// ErrNoCurrentURL is returned when the HX-Current-URL header is not present.
+	ErrNoCurrentURL = errors.New("no current URL")
+
+	// ErrInvalidCurrentURL is returned when the HX-Current-URL header is malformed or is not an absolute URL.
+	ErrInvalidCurrentURL = errors.New("invalid current URL")
+)
+
+// ParseCurrentURL parses the HX-Current-URL header, the URL of the browser when the request was made.
+//
+// HX-Current-URL is set by the client and must not be trusted. Use IsSameOrigin before redirecting
+// to the URL, or the WithTrustedOrigins middleware option.
+//
+// Returns:
+//
+//	*url.URL: The parsed URL.
+//	error: ErrNoCurrentURL if the header is not present, or an error matching ErrInvalidCurrentURL if
+//	the value cannot be parsed or is not an absolute URL with a scheme and host.
+//
+// Example usage:
+//
+//	h := middleware.FromRequest(r)
+//	if u, err := h.ParseCurrentURL(); err == nil {
+//		page := u.Query().Get("page")
+//	}
+func (h HTMXRequest) ParseCurrentURL() (*url.URL, error) {
+	if h.CurrentURL == "" {
+		return nil, ErrNoCurrentURL
+	}
+
+	u, err := url.Parse(h.CurrentURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCurrentURL, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("%w: %q is not an absolute URL", ErrInvalidCurrentURL, h.CurrentURL)
+	}
+	return u, nil
+}
+
+// CurrentQuery returns the query values of the HX-Current-URL header, such as the filters and
+// pagination of the page the request was made from. Errors are as for ParseCurrentURL.
+func (h HTMXRequest) CurrentQuery() (url.Values, error) {
+	u, err := h.ParseCurrentURL()
+	if err != nil {
+		return nil, err
+	}
+	return u.Query(), nil
+}
+
+// CurrentQueryValue returns the first value of the given query parameter of the HX-Current-URL header.
+// An empty string is returned if the parameter is not present, or the URL is missing or invalid.
+func (h HTMXRequest) CurrentQueryValue(key string) string {
+	query, err := h.CurrentQuery()
+	if err != nil {
+		return ""
+	}
+	return query.Get(key)
+}
+
+// IsSameOrigin reports whether the HX-Current-URL header has the same origin as the request: the
+// scheme, which is https if r.TLS is set and http otherwise, and r.Host. Default ports are ignored
+// and hosts are compared without regard to case. False is returned if the URL is missing or invalid.
+//
+// As HX-Current-URL is set by the client, this should be checked before redirecting to the URL to
+// prevent open redirects. Behind a proxy terminating TLS, r.TLS is not set, so the proxy must pass
+// the request over TLS, or WithTrustedOrigins should be used instead.
+//
+// Example usage:
+//
+//	h := middleware.FromRequest(r)
+//	if h.IsSameOrigin(r) {
+//		http.Redirect(w, r, h.CurrentURL, http.StatusSeeOther)
+//	}
+func (h HTMXRequest) IsSameOrigin(r *http.Request) bool {
+	u, err := h.ParseCurrentURL()
+	if err != nil {
+		return false
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return origin(u.Scheme, u.Host) == origin(scheme, r.Host)
+}
+
+// origin returns the lower case origin of the scheme and host, omitting the default port of the scheme.
+func origin(scheme, host string) string {
+	scheme, host = strings.ToLower(scheme), strings.ToLower(host)
+	switch {
+	case scheme == "http" && strings.HasSuffix(host, ":80"):
+		host = strings.TrimSuffix(host, ":80")
+	case scheme == "https" && strings.HasSuffix(host, ":443"):
+		host = strings.TrimSuffix(host, ":443")
+	}
+	return scheme + "://" + host
+}
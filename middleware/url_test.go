@@ -0,0 +1,136 @@
+package middleware_test
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thisisthemurph/hx/middleware"
+)
+
+func TestHTMXRequest_ParseCurrentURL(t *testing.T) {
+	testCases := []struct {
+		name        string
+		currentURL  string
+		expectedErr error
+	}{
+		{
+			name:       "absolute url",
+			currentURL: "https://example.com/items?page=2&sort=name",
+		}, {
+			name:        "missing",
+			expectedErr: middleware.ErrNoCurrentURL,
+		}, {
+			name:        "malformed",
+			currentURL:  "https://example.com/%zz",
+			expectedErr: middleware.ErrInvalidCurrentURL,
+		}, {
+			name:        "relative",
+			currentURL:  "/items?page=2",
+			expectedErr: middleware.ErrInvalidCurrentURL,
+		}, {
+			name:        "no host",
+			currentURL:  "mailto:someone@example.com",
+			expectedErr: middleware.ErrInvalidCurrentURL,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := middleware.HTMXRequest{CurrentURL: tc.currentURL}
+
+			u, err := h.ParseCurrentURL()
+			query, queryErr := h.CurrentQuery()
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				assert.ErrorIs(t, queryErr, tc.expectedErr)
+				assert.Nil(t, u)
+				assert.Empty(t, h.CurrentQueryValue("page"))
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.NoError(t, queryErr)
+			assert.Equal(t, "example.com", u.Host)
+			assert.Equal(t, "/items", u.Path)
+			assert.Equal(t, "name", query.Get("sort"))
+			assert.Equal(t, "2", h.CurrentQueryValue("page"))
+			assert.Empty(t, h.CurrentQueryValue("missing"))
+		})
+	}
+}
+
+func TestHTMXRequest_IsSameOrigin(t *testing.T) {
+	testCases := []struct {
+		name       string
+		currentURL string
+		host       string
+		tls        bool
+		expected   bool
+	}{
+		{
+			name:       "same origin",
+			currentURL: "http://example.com/items",
+			host:       "example.com",
+			expected:   true,
+		}, {
+			name:       "same origin over tls",
+			currentURL: "https://example.com/items",
+			host:       "example.com",
+			tls:        true,
+			expected:   true,
+		}, {
+			name:       "same origin with port",
+			currentURL: "http://localhost:8080/items",
+			host:       "localhost:8080",
+			expected:   true,
+		}, {
+			name:       "default port",
+			currentURL: "https://EXAMPLE.com:443/items",
+			host:       "example.com",
+			tls:        true,
+			expected:   true,
+		}, {
+			name:       "different host",
+			currentURL: "https://evil.example/items",
+			host:       "example.com",
+			tls:        true,
+		}, {
+			name:       "different scheme",
+			currentURL: "https://example.com/items",
+			host:       "example.com",
+		}, {
+			name:       "different port",
+			currentURL: "http://example.com:8080/items",
+			host:       "example.com",
+		}, {
+			name:       "host suffix",
+			currentURL: "http://example.com.evil.example/items",
+			host:       "example.com",
+		}, {
+			name:       "relative url",
+			currentURL: "/items",
+			host:       "example.com",
+		}, {
+			name: "missing",
+			host: "example.com",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Host = tc.host
+			req.TLS = nil
+			if tc.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+
+			h := middleware.HTMXRequest{CurrentURL: tc.currentURL}
+			assert.Equal(t, tc.expected, h.IsSameOrigin(req))
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package hx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ResponseHeaders is a struct detailing HTMX response header values.
+// It is the response counterpart of middleware.HTMXRequest, and is useful in tests and proxies.
+// HTMX documentation: https://htmx.org/reference/#response_headers
+type ResponseHeaders struct {
+	Location           *LocationContext // The HX-Location context; a bare path is returned as a context with only Path set. Nil if not present.
+	PushURL            string           // The URL pushed into the history stack, or "false" to prevent the history being updated.
+	ReplaceURL         string           // The URL replacing the current URL in the location bar, or "false" to prevent it being replaced.
+	Redirect           string           // The location of a client-side redirect.
+	Refresh            bool             // Indicates that the client-side will do a full refresh of the page.
+	Reswap             *SwapSpec        // How the response will be swapped. Nil if not present.
+	Retarget           string           // A CSS selector that updates the target of the content update.
+	Reselect           string           // A CSS selector choosing which part of the response is swapped in.
+	Trigger            []TriggerEvent   // Events triggered as soon as the response is received.
+	TriggerAfterSwap   []TriggerEvent   // Events triggered after the swap step.
+	TriggerAfterSettle []TriggerEvent   // Events triggered after the settle step.
+}
+
+// ParseResponseHeaders reads the HTMX response headers from the given http.Header.
+//
+// The trigger headers are decoded into TriggerEvents regardless of whether they use the comma separated
+// or JSON form, retaining the order in which the events appear in the header. Events in the comma
+// separated form, and JSON events with null detail, have a nil Detail; other detail is decoded as with
+// json.Unmarshal into an any value. A string target property of an object detail is read into Target,
+// as HTMX dispatches the event on that element, and a detail left with only a value property is
+// unwrapped, so that events written by NewTargetedTriggerEvent are read back unchanged.
+//
+// If any header cannot be parsed, the remaining headers are still parsed and all errors are returned
+// joined using errors.Join.
+//
+// Example usage:
+//
+//	w := httptest.NewRecorder()
+//	handler.ServeHTTP(w, r)
+//	headers, err := hx.ParseResponseHeaders(w.Header())
+func ParseResponseHeaders(h http.Header) (ResponseHeaders, error) {
+	res := ResponseHeaders{
+		PushURL:    h.Get(HeaderPushURL),
+		ReplaceURL: h.Get(HeaderReplaceURL),
+		Redirect:   h.Get(HeaderRedirect),
+		Refresh:    h.Get(HeaderRefresh) == "true",
+		Retarget:   h.Get(HeaderRetarget),
+		Reselect:   h.Get(HeaderReselect),
+	}
+	errs := make([]error, 0)
+
+	if value := h.Get(HeaderLocation); value != "" {
+		location, err := parseLocation(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", HeaderLocation, err))
+		} else {
+			res.Location = &location
+		}
+	}
+
+	if value := h.Get(HeaderReswap); value != "" {
+		spec, err := ParseSwapSpec(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", HeaderReswap, err))
+		} else {
+			res.Reswap = &spec
+		}
+	}
+
+	triggers := []struct {
+		header string
+		events *[]TriggerEvent
+	}{
+		{HeaderTrigger, &res.Trigger},
+		{HeaderTriggerAfterSwap, &res.TriggerAfterSwap},
+		{HeaderTriggerAfterSettle, &res.TriggerAfterSettle},
+	}
+	for _, t := range triggers {
+		events, err := parseTriggerHeader(h.Get(t.header))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", t.header, err))
+			continue
+		}
+		*t.events = events
+	}
+
+	return res, errors.Join(errs...)
+}
+
+// parseLocation parses the value of the HX-Location header, which is either a bare path or a JSON context object.
+func parseLocation(value string) (LocationContext, error) {
+	if !strings.HasPrefix(strings.TrimSpace(value), "{") {
+		return LocationContext{Path: value}, nil
+	}
+
+	var location LocationContext
+	if err := json.Unmarshal([]byte(value), &location); err != nil {
+		return LocationContext{}, err
+	}
+	return location, nil
+}
+
+// parseTriggerHeader parses the value of a trigger header into TriggerEvents, in the order they appear.
+// The value may be a JSON object of event names to detail, or a comma separated list of event names.
+// An empty value returns no events.
+func parseTriggerHeader(value string) ([]TriggerEvent, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(strings.TrimSpace(value), "{") {
+		events := make([]TriggerEvent, 0)
+		for _, ev := range strings.Split(value, ",") {
+			if eventName := strings.TrimSpace(ev); eventName != "" {
+				events = append(events, TriggerEvent{Name: eventName})
+			}
+		}
+		return events, nil
+	}
+
+	details, err := decodeOrderedObject([]byte(value))
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]TriggerEvent, 0, len(details))
+	for _, d := range details {
+		event, err := parseTriggerEvent(d)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// parseTriggerEvent decodes a member of a JSON trigger header into a TriggerEvent, reversing the
+// encoding of targeted events: a string target property of an object detail is moved into Target,
+// and a remaining detail of only a value property, as written for scalar detail, is unwrapped.
+func parseTriggerEvent(member orderedMember) (TriggerEvent, error) {
+	event := TriggerEvent{Name: member.key}
+	data := member.value
+
+	if isJSONObject(string(data)) {
+		members, err := decodeOrderedObject(data)
+		if err != nil {
+			return TriggerEvent{}, err
+		}
+		for i, m := range members {
+			var target string
+			if m.key != "target" || json.Unmarshal(m.value, &target) != nil {
+				continue
+			}
+			event.Target = target
+			members = append(members[:i], members[i+1:]...)
+
+			switch {
+			case len(members) == 0:
+				return event, nil
+			case len(members) == 1 && members[0].key == "value":
+				data = members[0].value
+			default:
+				if data, err = encodeOrderedObject(members); err != nil {
+					return TriggerEvent{}, err
+				}
+			}
+			break
+		}
+	}
+
+	if err := json.Unmarshal(data, &event.Detail); err != nil {
+		return TriggerEvent{}, err
+	}
+	return event, nil
+}
+
+type orderedMember struct {
+	key   string
+	value json.RawMessage
+}
+
+// decodeOrderedObject decodes a JSON object into its members, retaining the order of the keys.
+func decodeOrderedObject(data []byte) ([]orderedMember, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	if tok, err := dec.Token(); err != nil {
+		return nil, err
+	} else if tok != json.Delim('{') {
+		return nil, fmt.Errorf("expected a JSON object, got %v", tok)
+	}
+
+	members := make([]orderedMember, 0)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a JSON object key, got %v", tok)
+		}
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		members = append(members, orderedMember{key: key, value: value})
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, errors.New("unexpected data after JSON object")
+	}
+	return members, nil
+}
+
+// encodeOrderedObject encodes the members as a JSON object, retaining the order of the keys.
+func encodeOrderedObject(members []orderedMember) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range members {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(m.key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(m.value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,174 @@
+package hx_test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thisisthemurph/hx"
+)
+
+func TestParseResponseHeaders(t *testing.T) {
+	w := httptest.NewRecorder()
+	err := hx.SetHeaders(w,
+		hx.LocationWithContext(hx.LocationContext{Path: "/messages", Target: "#messages", Swap: hx.SwapOuterHTML}),
+		hx.PreventPushURL(),
+		hx.ReplaceURL("/replaced"),
+		hx.Redirect("/login"),
+		hx.Refresh(),
+		hx.ReswapWithSpec(hx.NewSwapSpec(hx.SwapBeforeEnd).WithSwapDelay(time.Second)),
+		hx.Retarget("#target"),
+		hx.Reselect("#content"),
+		hx.Trigger("event1", "event2"),
+		hx.TriggerAfterSwapWithDetail(hx.NewTriggerEvent("event3", map[string]any{"msg": "hello"})),
+		hx.TriggerAfterSettleWithDetail(hx.NewTriggerEvent("event4", "detail")),
+	)
+	assert.NoError(t, err)
+
+	headers, err := hx.ParseResponseHeaders(w.Header())
+
+	expectedSwap := hx.NewSwapSpec(hx.SwapBeforeEnd).WithSwapDelay(time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, hx.ResponseHeaders{
+		Location:   &hx.LocationContext{Path: "/messages", Target: "#messages", Swap: hx.SwapOuterHTML},
+		PushURL:    "false",
+		ReplaceURL: "/replaced",
+		Redirect:   "/login",
+		Refresh:    true,
+		Reswap:     &expectedSwap,
+		Retarget:   "#target",
+		Reselect:   "#content",
+		Trigger: []hx.TriggerEvent{
+			{Name: "event1"},
+			{Name: "event2"},
+		},
+		TriggerAfterSwap: []hx.TriggerEvent{
+			{Name: "event3", Detail: map[string]any{"msg": "hello"}},
+		},
+		TriggerAfterSettle: []hx.TriggerEvent{
+			{Name: "event4", Detail: "detail"},
+		},
+	}, headers)
+}
+
+func TestParseResponseHeaders_Empty(t *testing.T) {
+	headers, err := hx.ParseResponseHeaders(httptest.NewRecorder().Header())
+
+	assert.NoError(t, err)
+	assert.Equal(t, hx.ResponseHeaders{}, headers)
+}
+
+func TestParseResponseHeaders_BareLocation(t *testing.T) {
+	w := httptest.NewRecorder()
+	_ = hx.SetHeaders(w, hx.Location("/path"))
+
+	headers, err := hx.ParseResponseHeaders(w.Header())
+
+	assert.NoError(t, err)
+	assert.Equal(t, &hx.LocationContext{Path: "/path"}, headers.Location)
+}
+
+func TestParseResponseHeaders_RetainsJSONTriggerOrder(t *testing.T) {
+	w := httptest.NewRecorder()
+	w.Header().Set(hx.HeaderTrigger, `{"zebra":null,"apple":1,"mango":[1,2]}`)
+
+	headers, err := hx.ParseResponseHeaders(w.Header())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []hx.TriggerEvent{
+		{Name: "zebra"},
+		{Name: "apple", Detail: float64(1)},
+		{Name: "mango", Detail: []any{float64(1), float64(2)}},
+	}, headers.Trigger)
+}
+
+func TestParseResponseHeaders_ReturnsErrors(t *testing.T) {
+	w := httptest.NewRecorder()
+	w.Header().Set(hx.HeaderLocation, `{"path":`)
+	w.Header().Set(hx.HeaderReswap, "sideways")
+	w.Header().Set(hx.HeaderTrigger, `{"event1":}`)
+	w.Header().Set(hx.HeaderRetarget, "#target")
+
+	headers, err := hx.ParseResponseHeaders(w.Header())
+
+	assert.Error(t, err)
+	assert.Len(t, err.(interface{ Unwrap() []error }).Unwrap(), 3)
+	assert.Equal(t, "#target", headers.Retarget)
+}
+
+func TestParseResponseHeaders_LocationSwap(t *testing.T) {
+	spec := hx.NewSwapSpec(hx.SwapOuterHTML).WithSwapDelay(time.Second)
+
+	testCases := []struct {
+		name     string
+		value    string
+		expected *hx.LocationContext
+	}{
+		{
+			name:     "bare style",
+			value:    `{"path":"/a","swap":"outerHTML"}`,
+			expected: &hx.LocationContext{Path: "/a", Swap: hx.SwapOuterHTML},
+		}, {
+			name:     "with modifiers",
+			value:    `{"path":"/a","swap":"outerHTML swap:1s"}`,
+			expected: &hx.LocationContext{Path: "/a", Swap: hx.SwapOuterHTML, SwapSpec: &spec},
+		}, {
+			name:     "no swap",
+			value:    `{"path":"/a"}`,
+			expected: &hx.LocationContext{Path: "/a"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			w.Header().Set(hx.HeaderLocation, tc.value)
+
+			headers, err := hx.ParseResponseHeaders(w.Header())
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, headers.Location)
+
+			w = httptest.NewRecorder()
+			assert.NoError(t, hx.SetHeaders(w, hx.LocationWithContext(*headers.Location)))
+			assert.JSONEq(t, tc.value, w.Header().Get(hx.HeaderLocation))
+		})
+	}
+}
+
+func TestParseResponseHeaders_InvalidLocationSwap(t *testing.T) {
+	w := httptest.NewRecorder()
+	w.Header().Set(hx.HeaderLocation, `{"path":"/a","swap":"outerHTML swap:soon"}`)
+
+	headers, err := hx.ParseResponseHeaders(w.Header())
+
+	assert.Error(t, err)
+	assert.Nil(t, headers.Location)
+}
+
+func TestParseResponseHeaders_TargetedTriggerEvents(t *testing.T) {
+	events := []hx.TriggerEvent{
+		hx.NewTargetedTriggerEvent("noDetail", "#a", nil),
+		hx.NewTargetedTriggerEvent("scalar", "#b", "hello"),
+		hx.NewTargetedTriggerEvent("object", "#c", map[string]any{"count": float64(1)}),
+		hx.NewTriggerEvent("untargeted", map[string]any{"value": "kept"}),
+	}
+
+	w := httptest.NewRecorder()
+	assert.NoError(t, hx.SetHeaders(w, hx.TriggerWithDetail(events...)))
+
+	headers, err := hx.ParseResponseHeaders(w.Header())
+
+	assert.NoError(t, err)
+	assert.Equal(t, events, headers.Trigger)
+}
+
+func TestParseResponseHeaders_NonStringTargetIsDetail(t *testing.T) {
+	w := httptest.NewRecorder()
+	w.Header().Set(hx.HeaderTrigger, `{"event":{"target":1}}`)
+
+	headers, err := hx.ParseResponseHeaders(w.Header())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []hx.TriggerEvent{{Name: "event", Detail: map[string]any{"target": float64(1)}}}, headers.Trigger)
+}
@@ -0,0 +1,62 @@
+package hx
+
+import (
+	"net/http"
+
+	"github.com/thisisthemurph/hx/middleware"
+)
+
+// RedirectTo redirects the client to the given URL, choosing the mechanism based on the request.
+//
+// For HTMX requests, including boosted requests, the HX-Redirect header is set and the response is
+// written with 204 No Content, causing HTMX to do a full page navigation. For other requests,
+// including history restore requests which expect the page content, a 303 See Other redirect is written.
+//
+// The request headers are read using middleware.FromRequest, so the middleware is not required.
+//
+// Parameters:
+//
+//	w (http.ResponseWriter): The response writer; the response is written by RedirectTo.
+//	r (*http.Request): The request being redirected.
+//	url (string): The URL to redirect to.
+//
+// Returns:
+//
+//	error: An error if the HX-Redirect header could not be set, in which case nothing is written.
+//
+// Example usage:
+//
+//	func createItem(w http.ResponseWriter, r *http.Request) {
+//		// ...
+//		_ = hx.RedirectTo(w, r, "/items")
+//	}
+func RedirectTo(w http.ResponseWriter, r *http.Request, url string) error {
+	return redirectTo(w, r, url, Redirect(url))
+}
+
+// LocationTo redirects the client to the given path, as with RedirectTo, but uses the HX-Location
+// header for HTMX requests. HTMX then loads the new content without a full page reload, as though
+// following a boosted link.
+//
+// Example usage:
+//
+//	func createItem(w http.ResponseWriter, r *http.Request) {
+//		// ...
+//		_ = hx.LocationTo(w, r, "/items")
+//	}
+func LocationTo(w http.ResponseWriter, r *http.Request, path string) error {
+	return redirectTo(w, r, path, Location(path))
+}
+
+func redirectTo(w http.ResponseWriter, r *http.Request, url string, decorator HeaderDecorator) error {
+	if !middleware.FromRequest(r).IsPartial() {
+		http.Redirect(w, r, url, http.StatusSeeOther)
+		return nil
+	}
+
+	if err := SetHeaders(w, decorator); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
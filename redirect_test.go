@@ -0,0 +1,105 @@
+package hx_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thisisthemurph/hx"
+	"github.com/thisisthemurph/hx/middleware"
+)
+
+func TestRedirectTo(t *testing.T) {
+	testCases := []struct {
+		name             string
+		requestHeaders   map[string]string
+		withMiddleware   bool
+		redirect         func(w http.ResponseWriter, r *http.Request, url string) error
+		expectedStatus   int
+		expectedHeader   string
+		expectedLocation string
+	}{
+		{
+			name:             "full page request",
+			redirect:         hx.RedirectTo,
+			expectedStatus:   http.StatusSeeOther,
+			expectedLocation: "/items",
+		}, {
+			name:           "htmx request",
+			requestHeaders: map[string]string{"HX-Request": "true"},
+			redirect:       hx.RedirectTo,
+			expectedStatus: http.StatusNoContent,
+			expectedHeader: hx.HeaderRedirect,
+		}, {
+			name:           "boosted request with middleware",
+			requestHeaders: map[string]string{"HX-Request": "true", "HX-Boosted": "true"},
+			withMiddleware: true,
+			redirect:       hx.RedirectTo,
+			expectedStatus: http.StatusNoContent,
+			expectedHeader: hx.HeaderRedirect,
+		}, {
+			name:             "history restore request",
+			requestHeaders:   map[string]string{"HX-Request": "true", "HX-History-Restore-Request": "true"},
+			redirect:         hx.RedirectTo,
+			expectedStatus:   http.StatusSeeOther,
+			expectedLocation: "/items",
+		}, {
+			name:             "history restore request with middleware",
+			requestHeaders:   map[string]string{"HX-Request": "true", "HX-History-Restore-Request": "true"},
+			withMiddleware:   true,
+			redirect:         hx.LocationTo,
+			expectedStatus:   http.StatusSeeOther,
+			expectedLocation: "/items",
+		}, {
+			name:             "location full page request",
+			redirect:         hx.LocationTo,
+			expectedStatus:   http.StatusSeeOther,
+			expectedLocation: "/items",
+		}, {
+			name:           "location htmx request",
+			requestHeaders: map[string]string{"HX-Request": "true"},
+			redirect:       hx.LocationTo,
+			expectedStatus: http.StatusNoContent,
+			expectedHeader: hx.HeaderLocation,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/items/new", nil)
+			for key, value := range tc.requestHeaders {
+				r.Header.Set(key, value)
+			}
+			w := httptest.NewRecorder()
+
+			var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.NoError(t, tc.redirect(w, r, "/items"))
+			})
+			if tc.withMiddleware {
+				handler = middleware.WithHTMX(handler)
+			}
+			handler.ServeHTTP(w, r)
+
+			res := w.Result()
+			assert.Equal(t, tc.expectedStatus, res.StatusCode)
+			assert.Equal(t, tc.expectedLocation, res.Header.Get("Location"))
+			for _, header := range []string{hx.HeaderRedirect, hx.HeaderLocation} {
+				if header == tc.expectedHeader {
+					assert.Equal(t, "/items", res.Header.Get(header))
+				} else {
+					assert.Empty(t, res.Header.Get(header))
+				}
+			}
+		})
+	}
+}
+
+func TestRedirectTo_HeadersAlreadyWritten(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	r.Header.Set("HX-Request", "true")
+	w := hx.NewResponseWriter(httptest.NewRecorder())
+	w.WriteHeader(http.StatusOK)
+
+	assert.ErrorIs(t, hx.RedirectTo(w, r, "/items"), hx.ErrHeadersAlreadyWritten)
+}
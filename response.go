@@ -0,0 +1,240 @@
+package hx
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Response is a builder for fluently accumulating HTMX response headers.
+//
+// Decorators added to the Response are not applied until Apply is called, or until the
+// response is written with WriteHeader or Write. When applied, every decorator is run and all
+// errors are recorded; the headers are only updated if every decorator succeeds.
+//
+// Response implements http.ResponseWriter, so it can be used in place of the original writer.
+//
+// Example usage:
+//
+//	err := hx.NewResponse(w).
+//		Retarget("#errors").
+//		Reswap(hx.SwapOuterHTML).
+//		Trigger("validation-failed").
+//		Apply()
+type Response struct {
+	w          http.ResponseWriter
+	decorators []HeaderDecorator
+	sizeLimit  int
+	err        error
+}
+
+// NewResponse creates a new Response builder for the given http.ResponseWriter.
+func NewResponse(w http.ResponseWriter) *Response {
+	return &Response{w: w}
+}
+
+// With adds any number of HeaderDecorators to the Response.
+func (r *Response) With(decorators ...HeaderDecorator) *Response {
+	r.decorators = append(r.decorators, decorators...)
+	return r
+}
+
+// LimitHeaderSize sets the maximum total size in bytes of the HTMX headers of the Response.
+// If the limit is exceeded when the decorators are applied, a *HeaderSizeError is recorded and the
+// headers are not changed. A limit of zero, the default, disables the check. See LimitHeaderSize.
+func (r *Response) LimitHeaderSize(limit int) *Response {
+	r.sizeLimit = limit
+	return r
+}
+
+// Location adds the HX-Location header to the Response. See Location.
+func (r *Response) Location(location string) *Response {
+	return r.With(Location(location))
+}
+
+// LocationWithContext adds the HX-Location header with a context object to the Response.
+// See LocationWithContext.
+func (r *Response) LocationWithContext(ctx LocationContext) *Response {
+	return r.With(LocationWithContext(ctx))
+}
+
+// PushURL adds the HX-Push-Url header to the Response. See PushURL.
+func (r *Response) PushURL(url string) *Response {
+	return r.With(PushURL(url))
+}
+
+// PreventPushURL sets the HX-Push-Url header of the Response to "false". See PreventPushURL.
+func (r *Response) PreventPushURL() *Response {
+	return r.With(PreventPushURL())
+}
+
+// Redirect adds the HX-Redirect header to the Response. See Redirect.
+func (r *Response) Redirect(path string) *Response {
+	return r.With(Redirect(path))
+}
+
+// Refresh sets the HX-Refresh header of the Response to "true". See Refresh.
+func (r *Response) Refresh() *Response {
+	return r.With(Refresh())
+}
+
+// PreventRefresh sets the HX-Refresh header of the Response to "false". See PreventRefresh.
+func (r *Response) PreventRefresh() *Response {
+	return r.With(PreventRefresh())
+}
+
+// ReplaceURL adds the HX-Replace-Url header to the Response. See ReplaceURL.
+func (r *Response) ReplaceURL(url string) *Response {
+	return r.With(ReplaceURL(url))
+}
+
+// PreventReplaceURL sets the HX-Replace-Url header of the Response to "false". See PreventReplaceURL.
+func (r *Response) PreventReplaceURL() *Response {
+	return r.With(PreventReplaceURL())
+}
+
+// Reselect adds the HX-Reselect header to the Response. See Reselect.
+func (r *Response) Reselect(selector string) *Response {
+	return r.With(Reselect(selector))
+}
+
+// Reswap adds the HX-Reswap header to the Response. See Reswap.
+func (r *Response) Reswap(swap Swap) *Response {
+	return r.With(Reswap(swap))
+}
+
+// ReswapWithSpec adds the HX-Reswap header with swap modifiers to the Response. See ReswapWithSpec.
+func (r *Response) ReswapWithSpec(spec SwapSpec) *Response {
+	return r.With(ReswapWithSpec(spec))
+}
+
+// Retarget adds the HX-Retarget header to the Response. See Retarget.
+func (r *Response) Retarget(target string) *Response {
+	return r.With(Retarget(target))
+}
+
+// Trigger adds the given event names to the HX-Trigger header of the Response. See Trigger.
+func (r *Response) Trigger(eventNames ...string) *Response {
+	return r.With(Trigger(eventNames...))
+}
+
+// TriggerAfterSwap adds the given event names to the HX-Trigger-After-Swap header of the Response.
+// See TriggerAfterSwap.
+func (r *Response) TriggerAfterSwap(eventNames ...string) *Response {
+	return r.With(TriggerAfterSwap(eventNames...))
+}
+
+// TriggerAfterSettle adds the given event names to the HX-Trigger-After-Settle header of the Response.
+// See TriggerAfterSettle.
+func (r *Response) TriggerAfterSettle(eventNames ...string) *Response {
+	return r.With(TriggerAfterSettle(eventNames...))
+}
+
+// TriggerWithDetail adds the given events to the HX-Trigger header of the Response. See TriggerWithDetail.
+func (r *Response) TriggerWithDetail(events ...TriggerEvent) *Response {
+	return r.With(TriggerWithDetail(events...))
+}
+
+// TriggerAfterSwapWithDetail adds the given events to the HX-Trigger-After-Swap header of the Response.
+// See TriggerAfterSwapWithDetail.
+func (r *Response) TriggerAfterSwapWithDetail(events ...TriggerEvent) *Response {
+	return r.With(TriggerAfterSwapWithDetail(events...))
+}
+
+// TriggerAfterSettleWithDetail adds the given events to the HX-Trigger-After-Settle header of the Response.
+// See TriggerAfterSettleWithDetail.
+func (r *Response) TriggerAfterSettleWithDetail(events ...TriggerEvent) *Response {
+	return r.With(TriggerAfterSettleWithDetail(events...))
+}
+
+// Apply applies all accumulated decorators to the headers of the underlying http.ResponseWriter.
+//
+// Every decorator is run, even if an earlier decorator fails, and all errors are returned joined
+// using errors.Join. If any decorator fails, none of the headers are changed.
+// If the underlying writer is, or wraps, a ResponseWriter whose headers have already been written,
+// ErrHeadersAlreadyWritten is returned.
+// Once applied, the accumulated decorators are cleared, so Apply may be called again after adding
+// further decorators.
+func (r *Response) Apply() error {
+	if len(r.decorators) == 0 {
+		return nil
+	}
+
+	decorators := r.decorators
+	r.decorators = nil
+
+	if err := checkHeadersWritten(r.w); err != nil {
+		r.err = errors.Join(r.err, err)
+		return err
+	}
+
+	scratch := headerWriter{header: r.w.Header().Clone()}
+	errs := make([]error, 0)
+	for _, fn := range decorators {
+		if err := fn(scratch); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) == 0 && r.sizeLimit > 0 {
+		if err := checkHeaderSize(scratch.header, r.sizeLimit); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		r.err = errors.Join(r.err, err)
+		return err
+	}
+
+	replaceHeader(r.w.Header(), scratch.header)
+	return nil
+}
+
+// Err returns the errors recorded when applying decorators, including those applied
+// automatically by WriteHeader and Write.
+func (r *Response) Err() error {
+	return r.err
+}
+
+// Header returns the header map of the underlying http.ResponseWriter.
+func (r *Response) Header() http.Header {
+	return r.w.Header()
+}
+
+// WriteHeader applies any accumulated decorators and sends the HTTP response header with the
+// provided status code. Any errors applying the decorators are available from Err.
+func (r *Response) WriteHeader(statusCode int) {
+	_ = r.Apply()
+	r.w.WriteHeader(statusCode)
+}
+
+// Write applies any accumulated decorators and writes the data to the underlying http.ResponseWriter.
+// Any errors applying the decorators are available from Err.
+func (r *Response) Write(b []byte) (int, error) {
+	_ = r.Apply()
+	return r.w.Write(b)
+}
+
+// Unwrap returns the underlying http.ResponseWriter, allowing use with http.ResponseController.
+func (r *Response) Unwrap() http.ResponseWriter {
+	return r.w
+}
+
+// headerWriter is a HeaderResponseWriter backed by a standalone header map.
+type headerWriter struct {
+	header http.Header
+}
+
+func (w headerWriter) Header() http.Header {
+	return w.header
+}
+
+// replaceHeader replaces the contents of dst with the contents of src, retaining the dst map.
+func replaceHeader(dst, src http.Header) {
+	for key := range dst {
+		delete(dst, key)
+	}
+	for key, values := range src {
+		dst[key] = values
+	}
+}
@@ -0,0 +1,78 @@
+package hx_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thisisthemurph/hx"
+)
+
+func TestResponse_Apply(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	err := hx.NewResponse(w).
+		Retarget("#errors").
+		Reswap(hx.SwapOuterHTML).
+		Trigger("event1", "event2").
+		PushURL("/path").
+		Apply()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "#errors", w.Header().Get(hx.HeaderRetarget))
+	assert.Equal(t, "outerHTML", w.Header().Get(hx.HeaderReswap))
+	assert.Equal(t, "event1, event2", w.Header().Get(hx.HeaderTrigger))
+	assert.Equal(t, "/path", w.Header().Get(hx.HeaderPushURL))
+}
+
+func TestResponse_Apply_JoinsErrorsAndLeavesHeadersUnchanged(t *testing.T) {
+	w := httptest.NewRecorder()
+	w.Header().Set(hx.HeaderRetarget, "#original")
+
+	err := hx.NewResponse(w).
+		Retarget("#errors").
+		Reswap(hx.Swap(42)).
+		TriggerWithDetail(hx.NewTriggerEvent("event1", make(chan int))).
+		Apply()
+
+	assert.True(t, errors.Is(err, hx.ErrInvalidSwap))
+	assert.Len(t, err.(interface{ Unwrap() []error }).Unwrap(), 2)
+	assert.Equal(t, "#original", w.Header().Get(hx.HeaderRetarget))
+	assert.Empty(t, w.Header().Get(hx.HeaderReswap))
+	assert.Empty(t, w.Header().Get(hx.HeaderTrigger))
+}
+
+func TestResponse_AppliesOnWriteHeader(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	res := hx.NewResponse(w).Redirect("/login")
+	res.WriteHeader(http.StatusNoContent)
+
+	assert.NoError(t, res.Err())
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "/login", w.Result().Header.Get(hx.HeaderRedirect))
+}
+
+func TestResponse_AppliesOnWrite(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	res := hx.NewResponse(w).Trigger("loaded")
+	_, err := res.Write([]byte("<p>hello</p>"))
+
+	assert.NoError(t, err)
+	assert.NoError(t, res.Err())
+	assert.Equal(t, "loaded", w.Result().Header.Get(hx.HeaderTrigger))
+}
+
+func TestResponse_RecordsErrorOnWriteHeader(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	res := hx.NewResponse(w).Retarget("#target").Reswap(hx.Swap(42))
+	res.WriteHeader(http.StatusOK)
+
+	assert.True(t, errors.Is(res.Err(), hx.ErrInvalidSwap))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Result().Header.Get(hx.HeaderRetarget))
+}
@@ -0,0 +1,98 @@
+package hx
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// DefaultHeaderSizeLimit is a conservative limit for the total size of HTMX response headers.
+// Many proxies, such as nginx with its default proxy_buffer_size, reject responses whose headers
+// exceed 4KB in total, including headers not set by HTMX.
+const DefaultHeaderSizeLimit = 4096
+
+// ErrHeaderTooLarge is matched by every *HeaderSizeError.
+var ErrHeaderTooLarge = errors.New("HTMX headers exceed size limit")
+
+// HeaderSizeError is returned when the HTMX response headers exceed the configured size limit.
+type HeaderSizeError struct {
+	Limit   int    // The configured limit in bytes.
+	Size    int    // The total size of the HTMX headers in bytes.
+	Largest string // The name of the largest HTMX header.
+}
+
+func (e *HeaderSizeError) Error() string {
+	return fmt.Sprintf("HTMX headers are %d bytes, exceeding the limit of %d bytes; the largest header is %s", e.Size, e.Limit, e.Largest)
+}
+
+// Is reports whether the target is ErrHeaderTooLarge.
+func (e *HeaderSizeError) Is(target error) bool {
+	return target == ErrHeaderTooLarge
+}
+
+// HeaderSize returns the total size in bytes of the HTMX response headers in h, as written in
+// the response: the name, the ": " separator, the value and the trailing CRLF of each header.
+func HeaderSize(h http.Header) int {
+	size, _ := headerSize(h)
+	return size
+}
+
+// headerSize returns the total size of the HTMX response headers and the name of the largest header.
+func headerSize(h http.Header) (int, string) {
+	total, largestSize, largest := 0, 0, ""
+	for _, header := range responseHeaders {
+		size := 0
+		for _, value := range h.Values(header) {
+			size += len(header) + len(": ") + len(value) + len("\r\n")
+		}
+		if size > largestSize {
+			largestSize, largest = size, header
+		}
+		total += size
+	}
+	return total, largest
+}
+
+// checkHeaderSize returns a *HeaderSizeError if the HTMX headers in h exceed the limit.
+func checkHeaderSize(h http.Header, limit int) error {
+	size, largest := headerSize(h)
+	if size > limit {
+		return &HeaderSizeError{Limit: limit, Size: size, Largest: largest}
+	}
+	return nil
+}
+
+// LimitHeaderSize returns a function applying the given decorators and ensuring the total size of
+// the HTMX response headers does not exceed limit bytes. This prevents large trigger event detail
+// from causing proxies to reject the response.
+//
+// The decorators are applied atomically: if any decorator returns an error, or the resulting
+// headers exceed the limit, the headers are restored to their original state.
+//
+// The returned function will return a *HeaderSizeError, matching ErrHeaderTooLarge, if the limit is
+// exceeded, or the error of any failing decorator.
+//
+// Example usage:
+//
+//	event := hx.NewTriggerEvent("itemsLoaded", items)
+//	err := hx.SetHeaders(w, hx.LimitHeaderSize(hx.DefaultHeaderSizeLimit, hx.TriggerWithDetail(event)))
+//	if errors.Is(err, hx.ErrHeaderTooLarge) {
+//		// Render the items in the response body instead.
+//	}
+func LimitHeaderSize(limit int, decorators ...HeaderDecorator) HeaderDecorator {
+	return func(w HeaderResponseWriter) error {
+		snapshot := w.Header().Clone()
+		for _, fn := range decorators {
+			if err := fn(w); err != nil {
+				replaceHeader(w.Header(), snapshot)
+				return err
+			}
+		}
+
+		if err := checkHeaderSize(w.Header(), limit); err != nil {
+			replaceHeader(w.Header(), snapshot)
+			return err
+		}
+		return nil
+	}
+}
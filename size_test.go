@@ -0,0 +1,103 @@
+package hx_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thisisthemurph/hx"
+)
+
+func TestHeaderSize(t *testing.T) {
+	h := http.Header{}
+	h.Set("Content-Type", "text/html")
+	assert.Equal(t, 0, hx.HeaderSize(h))
+
+	h.Set(hx.HeaderRetarget, "#target")
+	h.Set(hx.HeaderTrigger, "event")
+	expected := len("HX-Retarget: #target\r\n") + len("HX-Trigger: event\r\n")
+	assert.Equal(t, expected, hx.HeaderSize(h))
+}
+
+func TestLimitHeaderSize(t *testing.T) {
+	large := strings.Repeat("a", 100)
+
+	testCases := []struct {
+		name            string
+		limit           int
+		decorators      []hx.HeaderDecorator
+		expectedErr     bool
+		expectedLargest string
+		expectedHeaders map[string]string
+	}{
+		{
+			name:            "within limit",
+			limit:           hx.DefaultHeaderSizeLimit,
+			decorators:      []hx.HeaderDecorator{hx.TriggerWithDetail(hx.NewTriggerEvent("event", large))},
+			expectedHeaders: map[string]string{hx.HeaderRetarget: "#existing", hx.HeaderTrigger: `{"event":"` + large + `"}`},
+		}, {
+			name:            "exceeds limit",
+			limit:           100,
+			decorators:      []hx.HeaderDecorator{hx.Reswap(hx.SwapOuterHTML), hx.TriggerWithDetail(hx.NewTriggerEvent("event", large))},
+			expectedErr:     true,
+			expectedLargest: hx.HeaderTrigger,
+			expectedHeaders: map[string]string{hx.HeaderRetarget: "#existing"},
+		}, {
+			name:            "existing headers count towards limit",
+			limit:           len("HX-Retarget: #existing\r\n"),
+			decorators:      []hx.HeaderDecorator{hx.Trigger("event")},
+			expectedErr:     true,
+			expectedLargest: hx.HeaderRetarget,
+			expectedHeaders: map[string]string{hx.HeaderRetarget: "#existing"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			w.Header().Set(hx.HeaderRetarget, "#existing")
+
+			err := hx.SetHeaders(w, hx.LimitHeaderSize(tc.limit, tc.decorators...))
+			if tc.expectedErr {
+				assert.ErrorIs(t, err, hx.ErrHeaderTooLarge)
+				var sizeErr *hx.HeaderSizeError
+				if assert.True(t, errors.As(err, &sizeErr)) {
+					assert.Equal(t, tc.limit, sizeErr.Limit)
+					assert.Greater(t, sizeErr.Size, tc.limit)
+					assert.Equal(t, tc.expectedLargest, sizeErr.Largest)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Len(t, w.Header(), len(tc.expectedHeaders))
+			for key, value := range tc.expectedHeaders {
+				assert.Equal(t, value, w.Header().Get(key))
+			}
+		})
+	}
+}
+
+func TestLimitHeaderSize_RestoresHeadersOnDecoratorError(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	err := hx.SetHeaders(w, hx.LimitHeaderSize(hx.DefaultHeaderSizeLimit, hx.Retarget("#target"), hx.Reswap(hx.Swap(-1))))
+	assert.ErrorIs(t, err, hx.ErrInvalidSwap)
+	assert.Empty(t, w.Header())
+}
+
+func TestResponse_LimitHeaderSize(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	err := hx.NewResponse(w).
+		LimitHeaderSize(64).
+		Retarget("#target").
+		TriggerWithDetail(hx.NewTriggerEvent("event", strings.Repeat("a", 100))).
+		Apply()
+
+	assert.ErrorIs(t, err, hx.ErrHeaderTooLarge)
+	assert.Empty(t, w.Header())
+}
@@ -0,0 +1,372 @@
+package hx
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ScrollPosition represents the position an element is scrolled to by the scroll and show swap modifiers.
+type ScrollPosition string
+
+const (
+	ScrollTop    ScrollPosition = "top"    // Scroll to the top of the element.
+	ScrollBottom ScrollPosition = "bottom" // Scroll to the bottom of the element.
+	ShowNone     ScrollPosition = "none"   // Disables the show behaviour; only valid for the show modifier.
+)
+
+// ScrollModifier represents the value of the scroll and show swap modifiers.
+// If Target is empty, the target of the swap is used.
+type ScrollModifier struct {
+	Target   string         // A CSS selector, "window" or empty for the swap target.
+	Position ScrollPosition // The position to scroll to.
+}
+
+// String returns the ScrollModifier in the form expected by HTMX, e.g. "#el:top" or "bottom".
+func (m ScrollModifier) String() string {
+	if m.Target == "" {
+		return string(m.Position)
+	}
+	return m.Target + ":" + string(m.Position)
+}
+
+// SwapSpec represents a complete hx-swap specification: the swap style along with any modifiers.
+// Modifiers left as nil are omitted from the specification, leaving HTMX to use its defaults.
+//
+// A SwapSpec can be built fluently from a Swap value:
+//
+//	spec := hx.NewSwapSpec(hx.SwapOuterHTML).
+//		WithSwapDelay(time.Second).
+//		WithScroll("#messages", hx.ScrollBottom).
+//		WithTransition(true)
+//
+// For more information see: https://htmx.org/attributes/hx-swap/
+type SwapSpec struct {
+	Style       Swap            // The swap style.
+	SwapDelay   *time.Duration  // swap:<time> - the time between clearing the old content and inserting the new.
+	SettleDelay *time.Duration  // settle:<time> - the time between inserting the new content and settling it.
+	Transition  *bool           // transition:<bool> - whether to use the View Transition API.
+	IgnoreTitle *bool           // ignoreTitle:<bool> - whether to ignore any title tag in the response.
+	Scroll      *ScrollModifier // scroll:<target>:<position> - scrolls the target element after the swap.
+	Show        *ScrollModifier // show:<target>:<position> - scrolls the target element into view after the swap.
+	FocusScroll *bool           // focus-scroll:<bool> - whether to scroll to the focused element after the swap.
+}
+
+// NewSwapSpec creates a new SwapSpec with the given swap style and no modifiers.
+func NewSwapSpec(style Swap) SwapSpec {
+	return SwapSpec{Style: style}
+}
+
+// WithSwapDelay returns a copy of the SwapSpec with the swap modifier set to the given duration.
+func (s SwapSpec) WithSwapDelay(d time.Duration) SwapSpec {
+	s.SwapDelay = &d
+	return s
+}
+
+// WithSettleDelay returns a copy of the SwapSpec with the settle modifier set to the given duration.
+func (s SwapSpec) WithSettleDelay(d time.Duration) SwapSpec {
+	s.SettleDelay = &d
+	return s
+}
+
+// WithTransition returns a copy of the SwapSpec with the transition modifier set.
+func (s SwapSpec) WithTransition(transition bool) SwapSpec {
+	s.Transition = &transition
+	return s
+}
+
+// WithIgnoreTitle returns a copy of the SwapSpec with the ignoreTitle modifier set.
+func (s SwapSpec) WithIgnoreTitle(ignore bool) SwapSpec {
+	s.IgnoreTitle = &ignore
+	return s
+}
+
+// WithScroll returns a copy of the SwapSpec with the scroll modifier set.
+// An empty target scrolls the target of the swap.
+func (s SwapSpec) WithScroll(target string, position ScrollPosition) SwapSpec {
+	s.Scroll = &ScrollModifier{Target: target, Position: position}
+	return s
+}
+
+// WithShow returns a copy of the SwapSpec with the show modifier set.
+// An empty target shows the target of the swap.
+func (s SwapSpec) WithShow(target string, position ScrollPosition) SwapSpec {
+	s.Show = &ScrollModifier{Target: target, Position: position}
+	return s
+}
+
+// WithFocusScroll returns a copy of the SwapSpec with the focus-scroll modifier set.
+func (s SwapSpec) WithFocusScroll(focusScroll bool) SwapSpec {
+	s.FocusScroll = &focusScroll
+	return s
+}
+
+// Validate returns an error if the SwapSpec cannot be written as a valid hx-swap specification:
+// if the style is not a built-in or registered swap style, in which case the error wraps
+// ErrInvalidSwap, if a delay is negative, or if the scroll or show modifier has an invalid
+// position or a target containing whitespace. ShowNone is only valid for the show modifier,
+// without a target.
+//
+// The String of a SwapSpec which passes Validate is accepted by HTMX and by ParseSwapSpec.
+func (s SwapSpec) Validate() error {
+	if err := s.Style.validate(); err != nil {
+		return err
+	}
+	if s.SwapDelay != nil && *s.SwapDelay < 0 {
+		return fmt.Errorf("invalid swap modifier: negative delay %s", *s.SwapDelay)
+	}
+	if s.SettleDelay != nil && *s.SettleDelay < 0 {
+		return fmt.Errorf("invalid settle modifier: negative delay %s", *s.SettleDelay)
+	}
+	if s.Scroll != nil {
+		if err := s.Scroll.validate(false); err != nil {
+			return fmt.Errorf("invalid scroll modifier: %w", err)
+		}
+	}
+	if s.Show != nil {
+		if err := s.Show.validate(true); err != nil {
+			return fmt.Errorf("invalid show modifier: %w", err)
+		}
+	}
+	return nil
+}
+
+// String returns the SwapSpec in the form expected by the hx-swap attribute and HX-Reswap header,
+// e.g. "outerHTML swap:1s scroll:#messages:bottom".
+func (s SwapSpec) String() string {
+	parts := []string{s.Style.String()}
+
+	if s.SwapDelay != nil {
+		parts = append(parts, "swap:"+formatInterval(*s.SwapDelay))
+	}
+	if s.SettleDelay != nil {
+		parts = append(parts, "settle:"+formatInterval(*s.SettleDelay))
+	}
+	if s.Transition != nil {
+		parts = append(parts, "transition:"+strconv.FormatBool(*s.Transition))
+	}
+	if s.IgnoreTitle != nil {
+		parts = append(parts, "ignoreTitle:"+strconv.FormatBool(*s.IgnoreTitle))
+	}
+	if s.Scroll != nil {
+		parts = append(parts, "scroll:"+s.Scroll.String())
+	}
+	if s.Show != nil {
+		parts = append(parts, "show:"+s.Show.String())
+	}
+	if s.FocusScroll != nil {
+		parts = append(parts, "focus-scroll:"+strconv.FormatBool(*s.FocusScroll))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// SwapSyntaxError describes a problem with a hx-swap specification, pointing at the offending token.
+type SwapSyntaxError struct {
+	Input  string // The full specification being parsed.
+	Token  string // The token that could not be parsed.
+	Offset int    // The byte offset of the token within Input.
+	Reason string // A description of the problem.
+}
+
+func (e *SwapSyntaxError) Error() string {
+	return fmt.Sprintf("invalid swap specification %q: token %q at offset %d: %s", e.Input, e.Token, e.Offset, e.Reason)
+}
+
+// ParseSwapSpec parses a hx-swap specification such as "outerHTML swap:1s scroll:top" into a SwapSpec.
+// The swap style may be omitted, in which case SwapInnerHTML is used.
+//
+// If the specification cannot be parsed, a *SwapSyntaxError is returned identifying the offending token.
+// Each modifier may only be given once. The String method of the returned SwapSpec produces an equivalent
+// specification that parses back to the same SwapSpec.
+func ParseSwapSpec(s string) (SwapSpec, error) {
+	spec := NewSwapSpec(SwapInnerHTML)
+	seen := make(map[string]bool)
+
+	for i, token := range tokenizeSwapSpec(s) {
+		syntaxError := func(reason string) error {
+			return &SwapSyntaxError{Input: s, Token: token.value, Offset: token.offset, Reason: reason}
+		}
+
+		name, value, found := strings.Cut(token.value, ":")
+		if !found || !isSwapModifier(name) {
+			if i != 0 {
+				return SwapSpec{}, syntaxError("unknown modifier")
+			}
+
+			style, ok := swapFromString(token.value)
+			if !ok {
+				return SwapSpec{}, syntaxError("unknown swap style")
+			}
+			spec.Style = style
+			continue
+		}
+
+		if seen[name] {
+			return SwapSpec{}, syntaxError("duplicate modifier")
+		}
+		seen[name] = true
+
+		if err := spec.setModifier(name, value); err != nil {
+			return SwapSpec{}, syntaxError(err.Error())
+		}
+	}
+
+	return spec, nil
+}
+
+type swapToken struct {
+	value  string
+	offset int
+}
+
+// tokenizeSwapSpec splits a swap specification on whitespace, recording the offset of each token.
+func tokenizeSwapSpec(s string) []swapToken {
+	tokens := make([]swapToken, 0)
+	start := -1
+	for i, r := range s {
+		if unicode.IsSpace(r) {
+			if start >= 0 {
+				tokens = append(tokens, swapToken{value: s[start:i], offset: start})
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, swapToken{value: s[start:], offset: start})
+	}
+	return tokens
+}
+
+func isSwapModifier(name string) bool {
+	switch name {
+	case "swap", "settle", "transition", "ignoreTitle", "scroll", "show", "focus-scroll":
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *SwapSpec) setModifier(name, value string) error {
+	switch name {
+	case "swap", "settle":
+		d, err := parseInterval(value)
+		if err != nil {
+			return err
+		}
+		if name == "swap" {
+			s.SwapDelay = &d
+		} else {
+			s.SettleDelay = &d
+		}
+	case "transition", "ignoreTitle", "focus-scroll":
+		if value != "true" && value != "false" {
+			return fmt.Errorf("expected true or false, got %q", value)
+		}
+		b := value == "true"
+		switch name {
+		case "transition":
+			s.Transition = &b
+		case "ignoreTitle":
+			s.IgnoreTitle = &b
+		default:
+			s.FocusScroll = &b
+		}
+	case "scroll", "show":
+		m, err := parseScrollModifier(value, name == "show")
+		if err != nil {
+			return err
+		}
+		if name == "scroll" {
+			s.Scroll = &m
+		} else {
+			s.Show = &m
+		}
+	}
+	return nil
+}
+
+// parseScrollModifier parses the value of a scroll or show modifier, e.g. "#el:top" or "bottom".
+// Only the final colon separated part is the position, allowing selectors to contain colons.
+func parseScrollModifier(value string, allowNone bool) (ScrollModifier, error) {
+	var m ScrollModifier
+	if i := strings.LastIndex(value, ":"); i >= 0 {
+		m.Target = value[:i]
+		m.Position = ScrollPosition(value[i+1:])
+		if m.Target == "" {
+			return ScrollModifier{}, fmt.Errorf("empty target in %q", value)
+		}
+	} else {
+		m.Position = ScrollPosition(value)
+	}
+
+	if err := m.validate(allowNone); err != nil {
+		return ScrollModifier{}, err
+	}
+	return m, nil
+}
+
+// validate returns an error if the position is not valid for the modifier, or the target contains
+// whitespace. ShowNone is only valid without a target, and only if allowNone is true.
+func (m ScrollModifier) validate(allowNone bool) error {
+	if strings.IndexFunc(m.Target, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("target %q contains whitespace", m.Target)
+	}
+
+	switch m.Position {
+	case ScrollTop, ScrollBottom:
+		return nil
+	case ShowNone:
+		if allowNone && m.Target == "" {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid scroll position %q", m.Position)
+}
+
+// formatInterval formats a duration as a HTMX time interval, e.g. "1s" or "500ms".
+func formatInterval(d time.Duration) string {
+	if d%time.Second == 0 {
+		return strconv.FormatInt(int64(d/time.Second), 10) + "s"
+	}
+	ms := float64(d) / float64(time.Millisecond)
+	return strconv.FormatFloat(ms, 'f', -1, 64) + "ms"
+}
+
+// parseInterval parses a HTMX time interval such as "1s", "500ms" or "2m".
+// A value without a unit is interpreted as milliseconds, as it is by HTMX. Only decimal numbers
+// are accepted, and intervals too long to be represented as a time.Duration are rejected.
+func parseInterval(value string) (time.Duration, error) {
+	unit := time.Millisecond
+	number := value
+
+	switch {
+	case strings.HasSuffix(value, "ms"):
+		number = strings.TrimSuffix(value, "ms")
+	case strings.HasSuffix(value, "s"):
+		number = strings.TrimSuffix(value, "s")
+		unit = time.Second
+	case strings.HasSuffix(value, "m"):
+		number = strings.TrimSuffix(value, "m")
+		unit = time.Minute
+	}
+
+	if number == "" || strings.Trim(number, "0123456789.") != "" {
+		return 0, fmt.Errorf("invalid time interval %q", value)
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time interval %q", value)
+	}
+	// float64(math.MaxInt64) rounds up to 2^63, which is itself out of range.
+	d := n * float64(unit)
+	if d >= math.MaxInt64 {
+		return 0, fmt.Errorf("time interval %q is too long", value)
+	}
+	return time.Duration(d), nil
+}
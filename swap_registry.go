@@ -0,0 +1,101 @@
+package hx
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// firstCustomSwap is the first value allocated to custom swap styles, leaving room for built-in styles.
+const firstCustomSwap Swap = 1 << 10
+
+var swapRegistry = struct {
+	sync.RWMutex
+	byName map[string]Swap
+	bySwap map[Swap]string
+	next   Swap
+}{
+	byName: make(map[string]Swap),
+	bySwap: make(map[Swap]string),
+	next:   firstCustomSwap,
+}
+
+// RegisterSwap registers a named swap style provided by a HTMX extension, such as idiomorph's
+// "morph", "morph:outerHTML" or "morph:innerHTML", and returns a Swap value representing it.
+//
+// The returned Swap can be used anywhere a built-in swap style is accepted, and is recognised by
+// SwapFromString and ParseSwapSpec. Registering a name that is already registered returns the
+// existing value.
+//
+// An error is returned if the name is empty, contains whitespace, is a built-in swap style or
+// would be confused with a swap modifier.
+//
+// Example usage:
+//
+//	var SwapMorph = hx.MustRegisterSwap("morph")
+//
+//	_ := hx.SetHeaders(w, hx.Reswap(SwapMorph))
+func RegisterSwap(name string) (Swap, error) {
+	if err := validateSwapName(name); err != nil {
+		return SwapInnerHTML, err
+	}
+
+	swapRegistry.Lock()
+	defer swapRegistry.Unlock()
+
+	if swap, ok := swapRegistry.byName[name]; ok {
+		return swap, nil
+	}
+
+	swap := swapRegistry.next
+	swapRegistry.next++
+	swapRegistry.byName[name] = swap
+	swapRegistry.bySwap[swap] = name
+	return swap, nil
+}
+
+// MustRegisterSwap is like RegisterSwap but panics if the name cannot be registered.
+// It simplifies the safe initialization of package level Swap variables.
+func MustRegisterSwap(name string) Swap {
+	swap, err := RegisterSwap(name)
+	if err != nil {
+		panic(err)
+	}
+	return swap
+}
+
+func validateSwapName(name string) error {
+	if name == "" {
+		return fmt.Errorf("invalid swap style name: name cannot be empty")
+	}
+	if strings.IndexFunc(name, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("invalid swap style name %q: name cannot contain whitespace", name)
+	}
+	if _, ok := builtinSwapFromString(name); ok {
+		return fmt.Errorf("invalid swap style name %q: name is a built-in swap style", name)
+	}
+	if prefix, _, _ := strings.Cut(name, ":"); isSwapModifier(prefix) {
+		return fmt.Errorf("invalid swap style name %q: name conflicts with the %q modifier", name, prefix)
+	}
+	return nil
+}
+
+func customSwapName(s Swap) (string, bool) {
+	swapRegistry.RLock()
+	defer swapRegistry.RUnlock()
+
+	name, ok := swapRegistry.bySwap[s]
+	return name, ok
+}
+
+func customSwapFromName(name string) (Swap, bool) {
+	swapRegistry.RLock()
+	defer swapRegistry.RUnlock()
+
+	swap, ok := swapRegistry.byName[name]
+	if !ok {
+		return SwapInnerHTML, false
+	}
+	return swap, true
+}
@@ -0,0 +1,73 @@
+package hx_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thisisthemurph/hx"
+)
+
+func TestSwapTextContent(t *testing.T) {
+	swap, err := hx.SwapFromString("textContent")
+
+	assert.NoError(t, err)
+	assert.Equal(t, hx.SwapTextContent, swap)
+	assert.Equal(t, "textContent", swap.String())
+}
+
+func TestRegisterSwap(t *testing.T) {
+	names := []string{"morph", "morph:outerHTML", "morph:innerHTML"}
+
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			swap, err := hx.RegisterSwap(name)
+			assert.NoError(t, err)
+			assert.Equal(t, name, swap.String())
+
+			parsed, err := hx.SwapFromString(name)
+			assert.NoError(t, err)
+			assert.Equal(t, swap, parsed)
+
+			w := httptest.NewRecorder()
+			err = hx.SetHeaders(w, hx.Reswap(swap))
+			assert.NoError(t, err)
+			assert.Equal(t, name, w.Header().Get(hx.HeaderReswap))
+		})
+	}
+}
+
+func TestRegisterSwap_ReturnsExistingValue(t *testing.T) {
+	first := hx.MustRegisterSwap("custom-swap")
+	second := hx.MustRegisterSwap("custom-swap")
+
+	assert.Equal(t, first, second)
+}
+
+func TestRegisterSwap_ReturnsError(t *testing.T) {
+	testCases := []string{
+		"",
+		"two words",
+		"outerHTML",
+		"textContent",
+		"swap:1s",
+		"scroll:top",
+	}
+
+	for _, name := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := hx.RegisterSwap(name)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseSwapSpec_WithCustomSwap(t *testing.T) {
+	swap := hx.MustRegisterSwap("morph:outerHTML")
+
+	spec, err := hx.ParseSwapSpec("morph:outerHTML settle:1s")
+
+	assert.NoError(t, err)
+	assert.Equal(t, swap, spec.Style)
+	assert.Equal(t, "morph:outerHTML settle:1s", spec.String())
+}
@@ -0,0 +1,241 @@
+package hx_test
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thisisthemurph/hx"
+)
+
+func TestSwapSpec_String(t *testing.T) {
+	testCases := []struct {
+		name     string
+		spec     hx.SwapSpec
+		expected string
+	}{
+		{
+			name:     "style only",
+			spec:     hx.NewSwapSpec(hx.SwapOuterHTML),
+			expected: "outerHTML",
+		}, {
+			name:     "timing",
+			spec:     hx.NewSwapSpec(hx.SwapInnerHTML).WithSwapDelay(time.Second).WithSettleDelay(200 * time.Millisecond),
+			expected: "innerHTML swap:1s settle:200ms",
+		}, {
+			name:     "scroll with target",
+			spec:     hx.NewSwapSpec(hx.SwapBeforeEnd).WithScroll("#el", hx.ScrollTop),
+			expected: "beforeend scroll:#el:top",
+		}, {
+			name:     "show without target",
+			spec:     hx.NewSwapSpec(hx.SwapAfterBegin).WithShow("", hx.ScrollBottom),
+			expected: "afterbegin show:bottom",
+		}, {
+			name: "all modifiers",
+			spec: hx.NewSwapSpec(hx.SwapOuterHTML).
+				WithSwapDelay(1500*time.Millisecond).
+				WithSettleDelay(0).
+				WithTransition(true).
+				WithIgnoreTitle(true).
+				WithScroll("window", hx.ScrollBottom).
+				WithShow("#el", hx.ScrollTop).
+				WithFocusScroll(false),
+			expected: "outerHTML swap:1500ms settle:0s transition:true ignoreTitle:true scroll:window:bottom show:#el:top focus-scroll:false",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.spec.String())
+		})
+	}
+}
+
+func TestParseSwapSpec(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected hx.SwapSpec
+	}{
+		{
+			value:    "outerHTML",
+			expected: hx.NewSwapSpec(hx.SwapOuterHTML),
+		}, {
+			value:    "swap:500ms",
+			expected: hx.NewSwapSpec(hx.SwapInnerHTML).WithSwapDelay(500 * time.Millisecond),
+		}, {
+			value:    "beforeend  settle:1s   scroll:#el:top",
+			expected: hx.NewSwapSpec(hx.SwapBeforeEnd).WithSettleDelay(time.Second).WithScroll("#el", hx.ScrollTop),
+		}, {
+			value:    "innerHTML show:window:bottom focus-scroll:true transition:true ignoreTitle:true",
+			expected: hx.NewSwapSpec(hx.SwapInnerHTML).WithShow("window", hx.ScrollBottom).WithFocusScroll(true).WithTransition(true).WithIgnoreTitle(true),
+		}, {
+			value:    "none show:none swap:100",
+			expected: hx.NewSwapSpec(hx.SwapNone).WithShow("", hx.ShowNone).WithSwapDelay(100 * time.Millisecond),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			spec, err := hx.ParseSwapSpec(tc.value)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, spec)
+		})
+	}
+}
+
+func TestParseSwapSpec_ReturnsError(t *testing.T) {
+	testCases := []string{
+		"sideways",
+		"outerHTML swap:fast",
+		"outerHTML settle:-1s",
+		"outerHTML settle:99999999999999s",
+		"outerHTML swap:1e30s",
+		"outerHTML swap:1e3",
+		"outerHTML swap:0x10ms",
+		"outerHTML swap:Infs",
+		"outerHTML swap:s",
+		"outerHTML transition:yes",
+		"outerHTML scroll:middle",
+		"outerHTML scroll:#el:none",
+		"outerHTML unknown:true",
+		"outerHTML innerHTML",
+	}
+
+	for _, value := range testCases {
+		t.Run(value, func(t *testing.T) {
+			_, err := hx.ParseSwapSpec(value)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseSwapSpec_ErrorPointsAtOffendingToken(t *testing.T) {
+	testCases := []struct {
+		value          string
+		expectedToken  string
+		expectedOffset int
+	}{
+		{value: "sideways", expectedToken: "sideways", expectedOffset: 0},
+		{value: "outerHTML swap:fast", expectedToken: "swap:fast", expectedOffset: 10},
+		{value: "outerHTML  swap:1s transition:yes", expectedToken: "transition:yes", expectedOffset: 19},
+		{value: "outerHTML swap:1s swap:2s", expectedToken: "swap:2s", expectedOffset: 18},
+		{value: "swap:1s outerHTML", expectedToken: "outerHTML", expectedOffset: 8},
+		{value: "outerHTML settle:99999999999999s", expectedToken: "settle:99999999999999s", expectedOffset: 10},
+		{value: "outerHTML swap:1e30s", expectedToken: "swap:1e30s", expectedOffset: 10},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			_, err := hx.ParseSwapSpec(tc.value)
+
+			var syntaxErr *hx.SwapSyntaxError
+			assert.True(t, errors.As(err, &syntaxErr))
+			assert.Equal(t, tc.value, syntaxErr.Input)
+			assert.Equal(t, tc.expectedToken, syntaxErr.Token)
+			assert.Equal(t, tc.expectedOffset, syntaxErr.Offset)
+		})
+	}
+}
+
+func TestParseSwapSpec_RoundTrips(t *testing.T) {
+	testCases := []string{
+		"innerHTML",
+		"outerHTML swap:500ms",
+		"beforeend swap:1s settle:200ms transition:true ignoreTitle:false scroll:#el:top show:window:bottom focus-scroll:true",
+		"afterend swap:2.5ms show:none",
+		"delete settle:0s scroll:bottom",
+	}
+
+	for _, value := range testCases {
+		t.Run(value, func(t *testing.T) {
+			spec, err := hx.ParseSwapSpec(value)
+			assert.NoError(t, err)
+			assert.Equal(t, value, spec.String())
+
+			reparsed, err := hx.ParseSwapSpec(spec.String())
+			assert.NoError(t, err)
+			assert.Equal(t, spec, reparsed)
+		})
+	}
+}
+
+func TestSwapFromString_ReturnsSyntaxErrorForModifiers(t *testing.T) {
+	swap, err := hx.SwapFromString("outerHTML swap:500ms")
+
+	var syntaxErr *hx.SwapSyntaxError
+	assert.True(t, errors.As(err, &syntaxErr))
+	assert.Equal(t, hx.SwapInnerHTML, swap)
+	assert.Equal(t, "swap:500ms", syntaxErr.Token)
+	assert.Equal(t, 10, syntaxErr.Offset)
+}
+
+func TestReswapWithSpec(t *testing.T) {
+	w := httptest.NewRecorder()
+	spec := hx.NewSwapSpec(hx.SwapOuterHTML).WithSwapDelay(time.Second).WithTransition(true)
+
+	err := hx.SetHeaders(w, hx.ReswapWithSpec(spec))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "outerHTML swap:1s transition:true", w.Header().Get(hx.HeaderReswap))
+}
+
+func TestSwapSpec_Validate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		spec        hx.SwapSpec
+		expectedErr bool
+	}{
+		{
+			name: "valid spec",
+			spec: hx.NewSwapSpec(hx.SwapOuterHTML).WithSwapDelay(time.Second).WithSettleDelay(0).WithScroll("#el", hx.ScrollTop).WithShow("", hx.ShowNone),
+		}, {
+			name:        "invalid style",
+			spec:        hx.NewSwapSpec(hx.Swap(-1)),
+			expectedErr: true,
+		}, {
+			name:        "negative swap delay",
+			spec:        hx.NewSwapSpec(hx.SwapOuterHTML).WithSwapDelay(-time.Second),
+			expectedErr: true,
+		}, {
+			name:        "negative settle delay",
+			spec:        hx.NewSwapSpec(hx.SwapOuterHTML).WithSettleDelay(-time.Millisecond),
+			expectedErr: true,
+		}, {
+			name:        "invalid scroll position",
+			spec:        hx.NewSwapSpec(hx.SwapOuterHTML).WithScroll("", "middle"),
+			expectedErr: true,
+		}, {
+			name:        "scroll none",
+			spec:        hx.NewSwapSpec(hx.SwapOuterHTML).WithScroll("#el", hx.ShowNone),
+			expectedErr: true,
+		}, {
+			name:        "show none with target",
+			spec:        hx.NewSwapSpec(hx.SwapOuterHTML).WithShow("#el", hx.ShowNone),
+			expectedErr: true,
+		}, {
+			name:        "target with whitespace",
+			spec:        hx.NewSwapSpec(hx.SwapOuterHTML).WithShow("#list .item", hx.ScrollTop),
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.spec.Validate()
+			if !tc.expectedErr {
+				assert.NoError(t, err)
+				_, parseErr := hx.ParseSwapSpec(tc.spec.String())
+				assert.NoError(t, parseErr)
+				return
+			}
+
+			assert.Error(t, err)
+			w := httptest.NewRecorder()
+			assert.Equal(t, err, hx.SetHeaders(w, hx.ReswapWithSpec(tc.spec)))
+			assert.Empty(t, w.Header().Get(hx.HeaderReswap))
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package hx
 
-import "fmt"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
 
 const (
 	HeaderLocation           = "HX-Location"             // HX-Location allows you to do a client-side redirect that does not do a full page reload.
@@ -33,12 +37,20 @@ const (
 	SwapAfterEnd
 	SwapDelete
 	SwapNone
+	SwapTextContent
 )
 
+// ErrInvalidSwap is returned when a Swap value is neither a built-in nor a registered swap style.
+var ErrInvalidSwap = errors.New("invalid Swap value")
+
 // String returns a string representation of the Swap value.
-// If the Swap value is not recognized, it returns "innerHTML" by default.
+// Custom swap styles registered with RegisterSwap return their registered name.
+// If the Swap value is not recognized, it returns "Swap(n)" where n is the integer value;
+// use IsValid to check the value before using it in a header.
 func (s Swap) String() string {
 	switch s {
+	case SwapInnerHTML:
+		return "innerHTML"
 	case SwapOuterHTML:
 		return "outerHTML"
 	case SwapBeforeBegin:
@@ -53,34 +65,113 @@ func (s Swap) String() string {
 		return "delete"
 	case SwapNone:
 		return "none"
+	case SwapTextContent:
+		return "textContent"
 	default:
-		return "innerHTML"
+		if name, ok := customSwapName(s); ok {
+			return name
+		}
+		return fmt.Sprintf("Swap(%d)", int(s))
+	}
+}
+
+// IsValid reports whether the Swap value is a built-in swap style or a style registered with RegisterSwap.
+func (s Swap) IsValid() bool {
+	if s >= SwapInnerHTML && s <= SwapTextContent {
+		return true
+	}
+	_, ok := customSwapName(s)
+	return ok
+}
+
+// validate returns an error wrapping ErrInvalidSwap if the Swap value is not valid.
+func (s Swap) validate() error {
+	if !s.IsValid() {
+		return fmt.Errorf("%w: %d", ErrInvalidSwap, int(s))
 	}
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// An error wrapping ErrInvalidSwap is returned if the Swap value is not valid.
+func (s Swap) MarshalText() ([]byte, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// The text must be a built-in or registered swap style, as accepted by SwapFromString.
+func (s *Swap) UnmarshalText(text []byte) error {
+	swap, err := SwapFromString(string(text))
+	if err != nil {
+		return err
+	}
+	*s = swap
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface, encoding the Swap as a JSON string.
+// An error wrapping ErrInvalidSwap is returned if the Swap value is not valid.
+func (s Swap) MarshalJSON() ([]byte, error) {
+	text, err := s.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
 }
 
 // SwapFromString converts a string representation to a Swap value.
 // If the provided string does not match any known Swap values, it returns SwapInnerHTML by default
-// along with an error indicating the invalid string value.
+// along with a *SwapSyntaxError indicating the invalid string value.
+//
+// Only bare swap styles are accepted; use ParseSwapSpec for specifications including modifiers.
 func SwapFromString(s string) (Swap, error) {
+	if swap, ok := swapFromString(s); ok {
+		return swap, nil
+	}
+
+	err := &SwapSyntaxError{Input: s, Token: s, Reason: "unknown swap style"}
+	if tokens := tokenizeSwapSpec(s); len(tokens) > 1 {
+		if _, ok := swapFromString(tokens[0].value); ok {
+			err.Token = tokens[1].value
+			err.Offset = tokens[1].offset
+			err.Reason = "unexpected modifier"
+		}
+	}
+	return SwapInnerHTML, err
+}
+
+func swapFromString(s string) (Swap, bool) {
+	if swap, ok := builtinSwapFromString(s); ok {
+		return swap, true
+	}
+	return customSwapFromName(s)
+}
+
+func builtinSwapFromString(s string) (Swap, bool) {
 	switch s {
 	case "innerHTML":
-		return SwapInnerHTML, nil
+		return SwapInnerHTML, true
 	case "outerHTML":
-		return SwapOuterHTML, nil
+		return SwapOuterHTML, true
 	case "beforebegin":
-		return SwapBeforeBegin, nil
+		return SwapBeforeBegin, true
 	case "afterbegin":
-		return SwapAfterBegin, nil
+		return SwapAfterBegin, true
 	case "beforeend":
-		return SwapBeforeEnd, nil
+		return SwapBeforeEnd, true
 	case "afterend":
-		return SwapAfterEnd, nil
+		return SwapAfterEnd, true
 	case "delete":
-		return SwapDelete, nil
+		return SwapDelete, true
 	case "none":
-		return SwapNone, nil
+		return SwapNone, true
+	case "textContent":
+		return SwapTextContent, true
 	default:
-		return SwapInnerHTML, fmt.Errorf("invalid Swap value: %q", s)
+		return SwapInnerHTML, false
 	}
 }
 
@@ -91,6 +182,46 @@ func StringToSwap(s string) (Swap, error) {
 	return SwapFromString(s)
 }
 
+// TriggerPhase represents when triggered events are dispatched on the client, determining
+// which of the trigger headers is used.
+type TriggerPhase int
+
+const (
+	TriggerPhaseImmediate   TriggerPhase = iota // Events are triggered as soon as the response is received; HX-Trigger.
+	TriggerPhaseAfterSwap                       // Events are triggered after the swap step; HX-Trigger-After-Swap.
+	TriggerPhaseAfterSettle                     // Events are triggered after the settle step; HX-Trigger-After-Settle.
+)
+
+// Header returns the name of the trigger header for the TriggerPhase.
+// If the TriggerPhase is not recognized, it returns HX-Trigger by default.
+func (p TriggerPhase) Header() string {
+	switch p {
+	case TriggerPhaseAfterSwap:
+		return HeaderTriggerAfterSwap
+	case TriggerPhaseAfterSettle:
+		return HeaderTriggerAfterSettle
+	default:
+		return HeaderTrigger
+	}
+}
+
+// DuplicatePolicy determines how an event is handled when an event with the same name
+// is already present in a trigger header.
+type DuplicatePolicy int
+
+const (
+	DuplicateKeepLast  DuplicatePolicy = iota // The detail of the new event replaces the existing detail; the existing position is kept.
+	DuplicateKeepFirst                        // The existing event is kept and the new event is discarded.
+	DuplicateError                            // An error wrapping ErrDuplicateEvent is returned.
+)
+
+// ErrDuplicateEvent is returned when an event is already present in a trigger header and
+// the DuplicateError policy is used.
+var ErrDuplicateEvent = errors.New("duplicate trigger event")
+
+// ErrInvalidEventName is returned when a trigger event name is empty or contains control characters.
+var ErrInvalidEventName = errors.New("invalid event name")
+
 // TriggerEvent represents an event to be added to one of the following trigger headers:
 //
 //   - HX-Trigger
@@ -99,6 +230,7 @@ func StringToSwap(s string) (Swap, error) {
 type TriggerEvent struct {
 	Name   string // Name of the event to be triggered.
 	Detail any    // Detail associated with the event.
+	Target string // Optional CSS selector of the element the event is dispatched on.
 }
 
 // NewTriggerEvent creates a new TriggerEvent struct with the given name and detail.
@@ -108,3 +240,142 @@ func NewTriggerEvent(name string, detail any) TriggerEvent {
 		Detail: detail,
 	}
 }
+
+// NewTargetedTriggerEvent creates a new TriggerEvent struct with the given name and detail,
+// dispatched on the element matching the target CSS selector rather than the triggering element.
+func NewTargetedTriggerEvent(name, target string, detail any) TriggerEvent {
+	return TriggerEvent{
+		Name:   name,
+		Detail: detail,
+		Target: target,
+	}
+}
+
+// detail returns the detail of the event as it should be encoded in a trigger header.
+//
+// If the event has a Target, it is merged into the detail as a "target" property:
+//
+//   - nil detail becomes {"target": target}.
+//   - Detail encoding to a JSON object, such as a struct or map, has the target property added.
+//   - Scalar detail, such as a string or number, becomes {"value": detail, "target": target},
+//     the same form HTMX uses for scalar detail on the client.
+//
+// An error is returned if the detail cannot be serialized, encodes to a JSON array, or already
+// has a different "target" property.
+func (e TriggerEvent) detail() (any, error) {
+	if e.Target == "" {
+		return e.Detail, nil
+	}
+
+	target, err := json.Marshal(e.Target)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(e.Detail)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []orderedMember
+	switch data[0] {
+	case 'n':
+		members = []orderedMember{}
+	case '{':
+		members, err = decodeOrderedObject(data)
+		if err != nil {
+			return nil, err
+		}
+		for i, m := range members {
+			if m.key != "target" {
+				continue
+			}
+			var existing string
+			if err := json.Unmarshal(m.value, &existing); err != nil || existing != e.Target {
+				return nil, fmt.Errorf("cannot add target %q to event %q: detail already has a target property", e.Target, e.Name)
+			}
+			members = append(members[:i], members[i+1:]...)
+			break
+		}
+	case '[':
+		return nil, fmt.Errorf("cannot add target %q to event %q: detail is a JSON array", e.Target, e.Name)
+	default:
+		members = []orderedMember{{key: "value", value: data}}
+	}
+
+	members = append(members, orderedMember{key: "target", value: target})
+	return encodeOrderedObject(members)
+}
+
+// LocationContext represents the context object accepted by the HX-Location header.
+// Only Path is required, all other values are optional and are omitted from the header when empty.
+//
+// The swap value may be given as a bare style using Swap, or with modifiers using SwapSpec, which
+// takes precedence when set. When decoding, a swap value with modifiers sets SwapSpec, and Swap to
+// its style; a bare style only sets Swap.
+//
+// For more information see: https://htmx.org/headers/hx-location/
+type LocationContext struct {
+	Path     string            `json:"path"`              // The URL to load the response from.
+	Source   string            `json:"source,omitempty"`  // The source element of the request.
+	Event    string            `json:"event,omitempty"`   // An event that "triggered" the request.
+	Handler  string            `json:"handler,omitempty"` // A callback that will handle the response HTML.
+	Target   string            `json:"target,omitempty"`  // The target to swap the response into.
+	Swap     Swap              `json:"swap,omitempty"`    // How the response will be swapped in relative to the target; SwapInnerHTML is omitted.
+	SwapSpec *SwapSpec         `json:"-"`                 // How the response will be swapped, including modifiers; encoded as swap.
+	Values   map[string]any    `json:"values,omitempty"`  // Values to submit with the request.
+	Headers  map[string]string `json:"headers,omitempty"` // Headers to submit with the request.
+	Select   string            `json:"select,omitempty"`  // Allows you to select the content you want swapped from a response.
+}
+
+// MarshalJSON implements the json.Marshaler interface, encoding SwapSpec, if set, or otherwise Swap
+// as the swap value. An error is returned if the swap value is not valid.
+func (c LocationContext) MarshalJSON() ([]byte, error) {
+	type plain LocationContext
+	v := struct {
+		plain
+		Swap string `json:"swap,omitempty"`
+	}{plain: plain(c)}
+
+	switch {
+	case c.SwapSpec != nil:
+		if err := c.SwapSpec.Validate(); err != nil {
+			return nil, err
+		}
+		v.Swap = c.SwapSpec.String()
+	case c.Swap != SwapInnerHTML:
+		text, err := c.Swap.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		v.Swap = string(text)
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. The swap value is parsed with ParseSwapSpec,
+// so it may include modifiers.
+func (c *LocationContext) UnmarshalJSON(data []byte) error {
+	type plain LocationContext
+	var v struct {
+		plain
+		Swap string `json:"swap"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*c = LocationContext(v.plain)
+	if v.Swap == "" {
+		return nil
+	}
+	spec, err := ParseSwapSpec(v.Swap)
+	if err != nil {
+		return err
+	}
+	c.Swap = spec.Style
+	if spec != NewSwapSpec(spec.Style) {
+		c.SwapSpec = &spec
+	}
+	return nil
+}
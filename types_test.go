@@ -0,0 +1,83 @@
+package hx_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thisisthemurph/hx"
+)
+
+func TestSwap_String_UnknownValue(t *testing.T) {
+	swap := hx.Swap(42)
+
+	assert.False(t, swap.IsValid())
+	assert.Equal(t, "Swap(42)", swap.String())
+}
+
+func TestReswap_ReturnsErrorForInvalidSwap(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	err := hx.SetHeaders(w, hx.Reswap(hx.Swap(42)))
+
+	assert.True(t, errors.Is(err, hx.ErrInvalidSwap))
+	assert.Empty(t, w.Header().Get(hx.HeaderReswap))
+}
+
+func TestReswapWithSpec_ReturnsErrorForInvalidSwap(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	err := hx.SetHeaders(w, hx.ReswapWithSpec(hx.NewSwapSpec(hx.Swap(-1))))
+
+	assert.True(t, errors.Is(err, hx.ErrInvalidSwap))
+	assert.Empty(t, w.Header().Get(hx.HeaderReswap))
+}
+
+func TestSwap_JSON(t *testing.T) {
+	type config struct {
+		Swap hx.Swap `json:"swap"`
+	}
+
+	data, err := json.Marshal(config{Swap: hx.SwapOuterHTML})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"swap":"outerHTML"}`, string(data))
+
+	var c config
+	err = json.Unmarshal([]byte(`{"swap":"beforeend"}`), &c)
+	assert.NoError(t, err)
+	assert.Equal(t, hx.SwapBeforeEnd, c.Swap)
+
+	err = json.Unmarshal([]byte(`{"swap":"sideways"}`), &c)
+	assert.Error(t, err)
+
+	_, err = json.Marshal(config{Swap: hx.Swap(42)})
+	assert.True(t, errors.Is(err, hx.ErrInvalidSwap))
+}
+
+func TestSwap_Text(t *testing.T) {
+	text, err := hx.SwapDelete.MarshalText()
+	assert.NoError(t, err)
+	assert.Equal(t, "delete", string(text))
+
+	var swap hx.Swap
+	err = swap.UnmarshalText([]byte("afterend"))
+	assert.NoError(t, err)
+	assert.Equal(t, hx.SwapAfterEnd, swap)
+
+	_, err = hx.Swap(42).MarshalText()
+	assert.True(t, errors.Is(err, hx.ErrInvalidSwap))
+}
+
+func TestLocationWithContext_ReturnsErrorForInvalidSwap(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	err := hx.SetHeaders(w, hx.LocationWithContext(hx.LocationContext{
+		Path: "/test",
+		Swap: hx.Swap(42),
+	}))
+
+	assert.True(t, errors.Is(err, hx.ErrInvalidSwap))
+	assert.Empty(t, w.Header().Get(hx.HeaderLocation))
+}
@@ -0,0 +1,122 @@
+package hx
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrHeaderConflict is matched by every *HeaderConflictError returned by Validate.
+var ErrHeaderConflict = errors.New("conflicting HTMX headers")
+
+// HeaderConflictError describes a combination of HTMX response headers where HTMX ignores
+// some of the headers, or where the headers have no effect.
+type HeaderConflictError struct {
+	Header  string   // The header causing the other headers to be ignored.
+	Ignored []string // The headers that are ignored by HTMX.
+	Reason  string   // A description of the conflict.
+}
+
+func (e *HeaderConflictError) Error() string {
+	return fmt.Sprintf("%s causes %s to be ignored: %s", e.Header, strings.Join(e.Ignored, ", "), e.Reason)
+}
+
+// Is reports whether the target is ErrHeaderConflict.
+func (e *HeaderConflictError) Is(target error) bool {
+	return target == ErrHeaderConflict
+}
+
+// swapHeaders are the headers only used when HTMX swaps the response content into the page.
+var swapHeaders = []string{
+	HeaderPushURL,
+	HeaderReplaceURL,
+	HeaderReswap,
+	HeaderRetarget,
+	HeaderReselect,
+	HeaderTriggerAfterSwap,
+	HeaderTriggerAfterSettle,
+}
+
+// Validate inspects the HTMX response headers and reports combinations where HTMX ignores some of
+// the headers, or where the headers have no effect. Each problem is reported as a *HeaderConflictError
+// and all problems are returned joined using errors.Join. Nil is returned if no problems are found.
+//
+// The following are reported:
+//
+//   - HX-Location, HX-Redirect and HX-Refresh: true navigate away from the page, so any lower
+//     precedence navigation headers and all swap related headers are ignored.
+//   - HX-Push-Url, including "false", takes precedence over HX-Replace-Url.
+//   - HX-Reswap: none means nothing is swapped, so HX-Retarget and HX-Reselect have no effect.
+//
+// Example usage:
+//
+//	if err := hx.Validate(w.Header()); errors.Is(err, hx.ErrHeaderConflict) {
+//		log.Println(err)
+//	}
+func Validate(h http.Header) error {
+	errs := make([]error, 0)
+	present := func(headers ...string) []string {
+		found := make([]string, 0)
+		for _, header := range headers {
+			if h.Get(header) != "" {
+				found = append(found, header)
+			}
+		}
+		return found
+	}
+	report := func(header string, ignored []string, reason string) {
+		if len(ignored) > 0 {
+			errs = append(errs, &HeaderConflictError{Header: header, Ignored: ignored, Reason: reason})
+		}
+	}
+
+	refresh := h.Get(HeaderRefresh) == "true"
+	switch {
+	case h.Get(HeaderLocation) != "":
+		ignored := present(HeaderRedirect)
+		if refresh {
+			ignored = append(ignored, HeaderRefresh)
+		}
+		report(HeaderLocation, append(ignored, present(swapHeaders...)...), "the response content is not swapped")
+	case h.Get(HeaderRedirect) != "":
+		ignored := make([]string, 0)
+		if refresh {
+			ignored = append(ignored, HeaderRefresh)
+		}
+		report(HeaderRedirect, append(ignored, present(swapHeaders...)...), "the browser navigates to a new page")
+	case refresh:
+		report(HeaderRefresh, present(swapHeaders...), "the browser does a full page refresh")
+	default:
+		if h.Get(HeaderPushURL) != "" {
+			report(HeaderPushURL, present(HeaderReplaceURL), "only one history update is made")
+		}
+		if spec, err := ParseSwapSpec(h.Get(HeaderReswap)); err == nil && spec.Style == SwapNone {
+			report(HeaderReswap, present(HeaderRetarget, HeaderReselect), "no content is swapped")
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// SetHeadersStrict sets custom HTTP headers in the provided http.ResponseWriter, as with
+// SetHeadersAtomic, and then validates the resulting headers using Validate.
+//
+// If any decorator returns an error, or the resulting headers conflict, the headers are restored
+// to their original state and the error is returned.
+//
+// Example usage:
+//
+//	err := hx.SetHeadersStrict(w, hx.Redirect("/login"), hx.Retarget("#form"))
+//	// errors.Is(err, hx.ErrHeaderConflict) == true
+func SetHeadersStrict(w HeaderResponseWriter, funcs ...HeaderDecorator) error {
+	snapshot := w.Header().Clone()
+	if err := SetHeadersAtomic(w, funcs...); err != nil {
+		return err
+	}
+	if err := Validate(w.Header()); err != nil {
+		replaceHeader(w.Header(), snapshot)
+		return err
+	}
+	return nil
+}
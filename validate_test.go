@@ -0,0 +1,110 @@
+package hx_test
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thisisthemurph/hx"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name            string
+		decorators      []hx.HeaderDecorator
+		expectedHeader  string
+		expectedIgnored []string
+	}{
+		{
+			name:       "no conflicts",
+			decorators: []hx.HeaderDecorator{hx.Retarget("#target"), hx.Reswap(hx.SwapOuterHTML), hx.PushURL("/path"), hx.Trigger("event")},
+		}, {
+			name:       "redirect only",
+			decorators: []hx.HeaderDecorator{hx.Redirect("/login"), hx.Trigger("event")},
+		}, {
+			name:       "prevent refresh",
+			decorators: []hx.HeaderDecorator{hx.PreventRefresh(), hx.PushURL("/path")},
+		}, {
+			name:            "redirect with retarget and reswap",
+			decorators:      []hx.HeaderDecorator{hx.Redirect("/login"), hx.Retarget("#target"), hx.Reswap(hx.SwapOuterHTML)},
+			expectedHeader:  hx.HeaderRedirect,
+			expectedIgnored: []string{hx.HeaderReswap, hx.HeaderRetarget},
+		}, {
+			name:            "redirect with refresh",
+			decorators:      []hx.HeaderDecorator{hx.Redirect("/login"), hx.Refresh()},
+			expectedHeader:  hx.HeaderRedirect,
+			expectedIgnored: []string{hx.HeaderRefresh},
+		}, {
+			name:            "refresh with push url",
+			decorators:      []hx.HeaderDecorator{hx.Refresh(), hx.PushURL("/path")},
+			expectedHeader:  hx.HeaderRefresh,
+			expectedIgnored: []string{hx.HeaderPushURL},
+		}, {
+			name:            "location with redirect and after swap trigger",
+			decorators:      []hx.HeaderDecorator{hx.Location("/path"), hx.Redirect("/login"), hx.TriggerAfterSwap("event")},
+			expectedHeader:  hx.HeaderLocation,
+			expectedIgnored: []string{hx.HeaderRedirect, hx.HeaderTriggerAfterSwap},
+		}, {
+			name:            "push url with replace url",
+			decorators:      []hx.HeaderDecorator{hx.PreventPushURL(), hx.ReplaceURL("/path")},
+			expectedHeader:  hx.HeaderPushURL,
+			expectedIgnored: []string{hx.HeaderReplaceURL},
+		}, {
+			name:            "reswap none with retarget",
+			decorators:      []hx.HeaderDecorator{hx.Reswap(hx.SwapNone), hx.Retarget("#target"), hx.Reselect("#content")},
+			expectedHeader:  hx.HeaderReswap,
+			expectedIgnored: []string{hx.HeaderRetarget, hx.HeaderReselect},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			assert.NoError(t, hx.SetHeaders(w, tc.decorators...))
+
+			err := hx.Validate(w.Header())
+
+			if tc.expectedHeader == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			var conflictErr *hx.HeaderConflictError
+			assert.True(t, errors.Is(err, hx.ErrHeaderConflict))
+			assert.True(t, errors.As(err, &conflictErr))
+			assert.Equal(t, tc.expectedHeader, conflictErr.Header)
+			assert.Equal(t, tc.expectedIgnored, conflictErr.Ignored)
+		})
+	}
+}
+
+func TestValidate_ReportsMultipleConflicts(t *testing.T) {
+	w := httptest.NewRecorder()
+	_ = hx.SetHeaders(w, hx.PushURL("/a"), hx.ReplaceURL("/b"), hx.Reswap(hx.SwapNone), hx.Retarget("#target"))
+
+	err := hx.Validate(w.Header())
+
+	assert.Len(t, err.(interface{ Unwrap() []error }).Unwrap(), 2)
+}
+
+func TestSetHeadersStrict(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	err := hx.SetHeadersStrict(w, hx.Retarget("#target"), hx.Reswap(hx.SwapOuterHTML))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "#target", w.Header().Get(hx.HeaderRetarget))
+}
+
+func TestSetHeadersStrict_RestoresHeadersOnConflict(t *testing.T) {
+	w := httptest.NewRecorder()
+	w.Header().Set(hx.HeaderTrigger, "existing")
+
+	err := hx.SetHeadersStrict(w, hx.Redirect("/login"), hx.Retarget("#form"))
+
+	assert.True(t, errors.Is(err, hx.ErrHeaderConflict))
+	assert.Empty(t, w.Header().Get(hx.HeaderRedirect))
+	assert.Empty(t, w.Header().Get(hx.HeaderRetarget))
+	assert.Equal(t, "existing", w.Header().Get(hx.HeaderTrigger))
+}
@@ -0,0 +1,126 @@
+package hx
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/thisisthemurph/hx/internal/hook"
+)
+
+// ErrHeadersAlreadyWritten is returned when attempting to set headers on a ResponseWriter
+// after the response headers have been written to the client.
+var ErrHeadersAlreadyWritten = errors.New("headers have already been written")
+
+// ResponseWriter wraps a http.ResponseWriter, tracking whether the response headers have been written.
+//
+// Once WriteHeader, Write or Flush has been called, net/http silently ignores any further changes to
+// the headers. When SetHeaders, SetHeadersAtomic or Response.Apply are given a ResponseWriter, or a
+// writer wrapping one, they return ErrHeadersAlreadyWritten rather than silently dropping the headers.
+//
+// If Strict is true, a panic occurs instead of returning the error. This is intended to surface
+// mistakes during development.
+//
+// A ResponseWriter has Flush and Hijack methods whether or not the underlying writer supports them.
+// The writer passed to handlers by TrackHeaders only implements http.Flusher and http.Hijacker if
+// the underlying writer does.
+type ResponseWriter struct {
+	http.ResponseWriter
+	Strict  bool // Panic rather than return ErrHeadersAlreadyWritten.
+	written bool
+}
+
+// NewResponseWriter wraps the given http.ResponseWriter in a ResponseWriter.
+func NewResponseWriter(w http.ResponseWriter) *ResponseWriter {
+	return &ResponseWriter{ResponseWriter: w}
+}
+
+// TrackHeaders is a middleware function wrapping the response writer in a ResponseWriter, so that
+// HTMX headers set after the response has been written are reported as ErrHeadersAlreadyWritten.
+func TrackHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(hook.Wrap(NewResponseWriter(w)), r)
+	})
+}
+
+// TrackHeadersStrict is a middleware function like TrackHeaders, but the ResponseWriter panics
+// when HTMX headers are set after the response has been written. Intended for use in development.
+func TrackHeadersStrict(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := NewResponseWriter(w)
+		rw.Strict = true
+		next.ServeHTTP(hook.Wrap(rw), r)
+	})
+}
+
+// HeadersWritten reports whether the response headers have been written.
+func (w *ResponseWriter) HeadersWritten() bool {
+	return w.written
+}
+
+// WriteHeader sends the HTTP response header with the provided status code.
+// Informational (1xx) status codes, other than 101 Switching Protocols, do not
+// mark the headers as written as further headers may still be sent.
+func (w *ResponseWriter) WriteHeader(statusCode int) {
+	if hook.WritesHeaders(statusCode) {
+		w.written = true
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write writes the data to the connection as part of the HTTP reply, writing the headers if
+// they have not already been written.
+func (w *ResponseWriter) Write(b []byte) (int, error) {
+	w.written = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush sends any buffered data to the client, if supported by the underlying http.ResponseWriter.
+func (w *ResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.written = true
+		f.Flush()
+	}
+}
+
+// Hijack lets the caller take over the connection, returning http.ErrNotSupported if the underlying
+// http.ResponseWriter does not support it. Once the connection is hijacked the headers are treated as written.
+func (w *ResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil {
+		w.written = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap returns the underlying http.ResponseWriter, allowing use with http.ResponseController.
+func (w *ResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// checkHeadersWritten returns ErrHeadersAlreadyWritten if w is, or wraps, a ResponseWriter
+// whose headers have been written. If the ResponseWriter is strict, a panic occurs instead.
+func checkHeadersWritten(w HeaderResponseWriter) error {
+	for w != nil {
+		switch rw := w.(type) {
+		case *ResponseWriter:
+			if !rw.HeadersWritten() {
+				return nil
+			}
+			if rw.Strict {
+				panic(ErrHeadersAlreadyWritten)
+			}
+			return ErrHeadersAlreadyWritten
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = rw.Unwrap()
+		default:
+			return nil
+		}
+	}
+	return nil
+}
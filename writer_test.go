@@ -0,0 +1,146 @@
+package hx_test
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thisisthemurph/hx"
+)
+
+func TestResponseWriter_SetHeadersBeforeWrite(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := hx.NewResponseWriter(rec)
+
+	err := hx.SetHeaders(w, hx.Retarget("#target"))
+	w.WriteHeader(http.StatusOK)
+
+	assert.NoError(t, err)
+	assert.True(t, w.HeadersWritten())
+	assert.Equal(t, "#target", rec.Result().Header.Get(hx.HeaderRetarget))
+}
+
+func TestResponseWriter_ReturnsErrorAfterWrite(t *testing.T) {
+	testCases := []struct {
+		name  string
+		write func(w http.ResponseWriter)
+	}{
+		{
+			name:  "WriteHeader",
+			write: func(w http.ResponseWriter) { w.WriteHeader(http.StatusOK) },
+		}, {
+			name:  "Write",
+			write: func(w http.ResponseWriter) { _, _ = w.Write([]byte("hello")) },
+		}, {
+			name:  "Flush",
+			write: func(w http.ResponseWriter) { w.(http.Flusher).Flush() },
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := hx.NewResponseWriter(httptest.NewRecorder())
+			tc.write(w)
+
+			assert.True(t, errors.Is(hx.SetHeaders(w, hx.Retarget("#target")), hx.ErrHeadersAlreadyWritten))
+			assert.True(t, errors.Is(hx.SetHeadersAtomic(w, hx.Retarget("#target")), hx.ErrHeadersAlreadyWritten))
+		})
+	}
+}
+
+func TestResponseWriter_InformationalStatusDoesNotWriteHeaders(t *testing.T) {
+	w := hx.NewResponseWriter(httptest.NewRecorder())
+
+	w.WriteHeader(http.StatusEarlyHints)
+
+	assert.False(t, w.HeadersWritten())
+	assert.NoError(t, hx.SetHeaders(w, hx.Retarget("#target")))
+}
+
+func TestResponseWriter_StrictPanics(t *testing.T) {
+	w := hx.NewResponseWriter(httptest.NewRecorder())
+	w.Strict = true
+	w.WriteHeader(http.StatusOK)
+
+	assert.PanicsWithValue(t, hx.ErrHeadersAlreadyWritten, func() {
+		_ = hx.SetHeaders(w, hx.Retarget("#target"))
+	})
+}
+
+func TestResponseWriter_DetectedThroughWrappingWriters(t *testing.T) {
+	w := hx.NewResponseWriter(httptest.NewRecorder())
+	res := hx.NewResponse(w)
+	_, _ = res.Write([]byte("hello"))
+
+	err := res.Retarget("#target").Apply()
+
+	assert.True(t, errors.Is(err, hx.ErrHeadersAlreadyWritten))
+	assert.True(t, errors.Is(hx.SetHeaders(res, hx.Retarget("#target")), hx.ErrHeadersAlreadyWritten))
+}
+
+func TestTrackHeaders(t *testing.T) {
+	var err error
+	handler := hx.TrackHeaders(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		err = hx.SetHeaders(w, hx.Trigger("too-late"))
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.True(t, errors.Is(err, hx.ErrHeadersAlreadyWritten))
+}
+
+func TestTrackHeadersStrict(t *testing.T) {
+	handler := hx.TrackHeadersStrict(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_ = hx.SetHeaders(w, hx.Trigger("too-late"))
+	}))
+
+	assert.Panics(t, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
+
+// hijackRecorder is a httptest.ResponseRecorder implementing http.Hijacker.
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (r *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	r.hijacked = true
+	return nil, nil, nil
+}
+
+func TestTrackHeaders_KeepsHijacker(t *testing.T) {
+	rec := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+	var err error
+	handler := hx.TrackHeaders(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hijacker, ok := w.(http.Hijacker)
+		if !assert.True(t, ok) {
+			return
+		}
+		_, _, _ = hijacker.Hijack()
+		err = hx.SetHeaders(w, hx.Trigger("too-late"))
+	}))
+
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.True(t, rec.hijacked)
+	assert.True(t, errors.Is(err, hx.ErrHeadersAlreadyWritten))
+}
+
+func TestTrackHeaders_OnlyExposesSupportedInterfaces(t *testing.T) {
+	handler := hx.TrackHeaders(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, isFlusher := w.(http.Flusher)
+		_, isHijacker := w.(http.Hijacker)
+		assert.False(t, isFlusher)
+		assert.False(t, isHijacker)
+	}))
+
+	handler.ServeHTTP(struct{ http.ResponseWriter }{httptest.NewRecorder()}, httptest.NewRequest(http.MethodGet, "/", nil))
+}